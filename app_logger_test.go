@@ -0,0 +1,11 @@
+package logger
+
+// appLogger is a helper wrapping a Logger, as the applications do,
+// in its own file to tell its caller from the test one.
+type appLogger struct {
+	l Logger
+}
+
+func (a appLogger) info(msg string) {
+	a.l.Info(msg)
+}
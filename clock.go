@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock provides the time used to timestamp log entries.
+type Clock interface {
+	Now() time.Time
+}
+
+// ManualClock is a clock that only moves when told to.
+// It is useful for tests asserting on entry timestamps.
+type ManualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewManualClock creates a manual clock set at the given time.
+func NewManualClock(now time.Time) *ManualClock {
+	return &ManualClock{now: now}
+}
+
+// Now returns the current clock time.
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Set sets the current clock time.
+func (c *ManualClock) Set(now time.Time) {
+	c.mu.Lock()
+	c.now = now
+	c.mu.Unlock()
+}
+
+// Add moves the clock forward by d.
+func (c *ManualClock) Add(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+// fixedClock is a clock always returning the same time.
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+// zapClock adapts a Clock to the zapcore.Clock interface.
+type zapClock struct {
+	Clock
+}
+
+func (c zapClock) NewTicker(d time.Duration) *time.Ticker {
+	return time.NewTicker(d)
+}
@@ -0,0 +1,240 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/multierr"
+	"gopkg.in/yaml.v3"
+)
+
+// Config file formats, see ParseConfig.
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+// fileConfig is the Config read from a file, its keys
+// being the snake case names of the Config fields.
+type fileConfig struct {
+	Mode                   string                 `json:"mode" yaml:"mode"`
+	Level                  string                 `json:"level" yaml:"level"`
+	Encoding               string                 `json:"encoding" yaml:"encoding"`
+	TimeFormat             string                 `json:"time_format" yaml:"time_format"`
+	TimeKey                string                 `json:"time_key" yaml:"time_key"`
+	DurationFormat         string                 `json:"duration_format" yaml:"duration_format"`
+	FieldKeys              fileFieldKeys          `json:"field_keys" yaml:"field_keys"`
+	InitialFields          map[string]interface{} `json:"initial_fields" yaml:"initial_fields"`
+	DisableInitialFields   bool                   `json:"disable_initial_fields" yaml:"disable_initial_fields"`
+	OutputPaths            []string               `json:"output_paths" yaml:"output_paths"`
+	ErrorOutputPaths       []string               `json:"error_output_paths" yaml:"error_output_paths"`
+	Outputs                []fileOutput           `json:"outputs" yaml:"outputs"`
+	SkipDefaultMiddlewares bool                   `json:"skip_default_middlewares" yaml:"skip_default_middlewares"`
+	DisableCaller          bool                   `json:"disable_caller" yaml:"disable_caller"`
+	CallerSkip             int                    `json:"caller_skip" yaml:"caller_skip"`
+	Sampling               *fileSampling          `json:"sampling" yaml:"sampling"`
+	DisableStacktrace      bool                   `json:"disable_stacktrace" yaml:"disable_stacktrace"`
+	StacktraceLevel        string                 `json:"stacktrace_level" yaml:"stacktrace_level"`
+	RedactKeys             []string               `json:"redact_keys" yaml:"redact_keys"`
+	MaskOutput             bool                   `json:"mask_output" yaml:"mask_output"`
+	MaskFieldValues        bool                   `json:"mask_field_values" yaml:"mask_field_values"`
+	StrictFields           bool                   `json:"strict_fields" yaml:"strict_fields"`
+	AllowedFieldKeys       []string               `json:"allowed_field_keys" yaml:"allowed_field_keys"`
+	CountDroppedFields     bool                   `json:"count_dropped_fields" yaml:"count_dropped_fields"`
+}
+
+type fileFieldKeys struct {
+	MessageKey    string `json:"message_key" yaml:"message_key"`
+	LevelKey      string `json:"level_key" yaml:"level_key"`
+	TimeKey       string `json:"time_key" yaml:"time_key"`
+	CallerKey     string `json:"caller_key" yaml:"caller_key"`
+	StacktraceKey string `json:"stacktrace_key" yaml:"stacktrace_key"`
+	NameKey       string `json:"name_key" yaml:"name_key"`
+}
+
+type fileOutput struct {
+	Path     string `json:"path" yaml:"path"`
+	MinLevel string `json:"min_level" yaml:"min_level"`
+}
+
+type fileSampling struct {
+	Initial    int    `json:"initial" yaml:"initial"`
+	Thereafter int    `json:"thereafter" yaml:"thereafter"`
+	Tick       string `json:"tick" yaml:"tick"`
+	MaxLevel   string `json:"max_level" yaml:"max_level"`
+}
+
+// LoadConfig returns the Config read from a JSON or YAML file, the
+// format being the one of its ".json", ".yaml" or ".yml" extension:
+//
+//	cfg, err := logger.LoadConfig("/etc/app/logging.yaml")
+//	if err != nil {
+//		...
+//	}
+//	cfg.CtxMiddlewares = append(cfg.CtxMiddlewares, tenantMiddleware)
+//	lg, err := logger.New(cfg)
+//
+// See ParseConfig.
+func LoadConfig(path string) (Config, error) {
+	var format string
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		format = FormatJSON
+	case ".yaml", ".yml":
+		format = FormatYAML
+	default:
+		return Config{}, fmt.Errorf("config file %s: unknown extension %q, use .json, .yaml or .yml", path, ext)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("reading config file: %w", err)
+	}
+	cfg, err := ParseConfig(data, format)
+	if err != nil {
+		return Config{}, fmt.Errorf("config file %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// ParseConfig returns the Config read from data in the FormatJSON or
+// FormatYAML format. The keys are the snake case names of the Config
+// fields, e.g.:
+//
+//	mode: dev
+//	level: info
+//	output_paths: [stdout, /var/log/app.log]
+//	disable_stacktrace: true
+//	sampling:
+//	  initial: 100
+//	  thereafter: 100
+//	  tick: 1s
+//
+// The mode is "dev" or "development" for ModeDevelopment, "prod" or
+// "production" for the default one, the levels are parsed using
+// ParseLevel. The unknown keys are errors. The fields that can't be
+// read from a file, like CtxMiddlewares, Clock or Masker, are left
+// empty for the caller to set. An error naming every invalid value
+// is returned, the Config is validated, see Config.Validate.
+func ParseConfig(data []byte, format string) (Config, error) {
+	var fc fileConfig
+	switch strings.ToLower(format) {
+	case FormatJSON:
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&fc); err != nil {
+			return Config{}, fmt.Errorf("parsing json config: %w", err)
+		}
+	case FormatYAML, "yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		// An empty document is an empty config.
+		if err := dec.Decode(&fc); err != nil && !errors.Is(err, io.EOF) {
+			return Config{}, fmt.Errorf("parsing yaml config: %w", yamlError(err))
+		}
+	default:
+		return Config{}, fmt.Errorf("unknown config format %q, use %q or %q", format, FormatJSON, FormatYAML)
+	}
+	cfg, err := fc.config()
+	if err != nil {
+		return Config{}, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// yamlError returns err with the unknown keys errors
+// naming the keys rather than the fileConfig types.
+func yamlError(err error) error {
+	var terr *yaml.TypeError
+	if !errors.As(err, &terr) {
+		return err
+	}
+	var errs error
+	for _, e := range terr.Errors {
+		if i := strings.Index(e, ": field "); i >= 0 {
+			if j := strings.Index(e, " not found in type "); j > i {
+				e = e[:i] + ": unknown key " + strconv.Quote(e[i+len(": field "):j])
+			}
+		}
+		errs = multierr.Append(errs, errors.New(e))
+	}
+	return errs
+}
+
+// config returns the Config of the file, with an
+// error naming the key of every invalid value.
+func (fc fileConfig) config() (Config, error) {
+	cfg := Config{
+		Encoding:               fc.Encoding,
+		TimeFormat:             fc.TimeFormat,
+		TimeKey:                fc.TimeKey,
+		DurationFormat:         fc.DurationFormat,
+		FieldKeys:              FieldKeys(fc.FieldKeys),
+		InitialFields:          fc.InitialFields,
+		DisableInitialFields:   fc.DisableInitialFields,
+		OutputPaths:            fc.OutputPaths,
+		ErrorOutputPaths:       fc.ErrorOutputPaths,
+		SkipDefaultMiddlewares: fc.SkipDefaultMiddlewares,
+		DisableCaller:          fc.DisableCaller,
+		CallerSkip:             fc.CallerSkip,
+		DisableStacktrace:      fc.DisableStacktrace,
+		RedactKeys:             fc.RedactKeys,
+		MaskOutput:             fc.MaskOutput,
+		MaskFieldValues:        fc.MaskFieldValues,
+		StrictFields:           fc.StrictFields,
+		AllowedFieldKeys:       fc.AllowedFieldKeys,
+		CountDroppedFields:     fc.CountDroppedFields,
+	}
+	var errs error
+	parseLevel := func(key, s string) Level {
+		level, err := ParseLevel(s)
+		if err != nil {
+			errs = multierr.Append(errs, fmt.Errorf("%s: %w", key, err))
+		}
+		return level
+	}
+	mode, err := parseMode(fc.Mode)
+	if err != nil {
+		errs = multierr.Append(errs, fmt.Errorf("mode: %w", err))
+	}
+	cfg.Mode = mode
+	if fc.Level != "" {
+		cfg.Level = parseLevel("level", fc.Level)
+	}
+	if fc.StacktraceLevel != "" {
+		level := parseLevel("stacktrace_level", fc.StacktraceLevel)
+		cfg.StacktraceLevel = &level
+	}
+	for i, o := range fc.Outputs {
+		out := Output{Path: o.Path}
+		if o.MinLevel != "" {
+			out.MinLevel = parseLevel(fmt.Sprintf("outputs[%d].min_level", i), o.MinLevel)
+		}
+		cfg.Outputs = append(cfg.Outputs, out)
+	}
+	if s := fc.Sampling; s != nil {
+		cfg.Sampling = &SamplingConfig{Initial: s.Initial, Thereafter: s.Thereafter}
+		if s.Tick != "" {
+			tick, err := time.ParseDuration(s.Tick)
+			if err != nil {
+				errs = multierr.Append(errs, fmt.Errorf("sampling.tick: %w", err))
+			}
+			cfg.Sampling.Tick = tick
+		}
+		if s.MaxLevel != "" {
+			level := parseLevel("sampling.max_level", s.MaxLevel)
+			cfg.Sampling.MaxLevel = &level
+		}
+	}
+	return cfg, errs
+}
@@ -0,0 +1,132 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/multierr"
+)
+
+const yamlConfig = `
+mode: Dev
+level: warn
+output_paths: [stdout]
+disable_stacktrace: true
+stacktrace_level: error
+outputs:
+  - path: stderr
+    min_level: "3"
+sampling:
+  initial: 10
+  thereafter: 100
+  tick: 1s
+  max_level: info
+`
+
+const jsonConfig = `{
+	"mode": "Dev",
+	"level": "warn",
+	"output_paths": ["stdout"],
+	"disable_stacktrace": true,
+	"stacktrace_level": "error",
+	"outputs": [{"path": "stderr", "min_level": "3"}],
+	"sampling": {"initial": 10, "thereafter": 100, "tick": "1s", "max_level": "info"}
+}`
+
+func TestLoadConfig(t *testing.T) {
+	errorLevel, infoLevel := ErrorLevel, InfoLevel
+	want := Config{
+		Mode:              ModeDevelopment,
+		Level:             WarningLevel,
+		OutputPaths:       []string{"stdout"},
+		DisableStacktrace: true,
+		StacktraceLevel:   &errorLevel,
+		Outputs:           []Output{{Path: "stderr", MinLevel: ErrorLevel}},
+		Sampling:          &SamplingConfig{Initial: 10, Thereafter: 100, Tick: time.Second, MaxLevel: &infoLevel},
+	}
+
+	dir := t.TempDir()
+	for name, data := range map[string]string{"app.json": jsonConfig, "app.yaml": yamlConfig, "app.yml": yamlConfig} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := LoadConfig(path)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !reflect.DeepEqual(cfg, want) {
+			t.Errorf("%s: got %+v, want %+v", name, cfg, want)
+		}
+	}
+
+	if _, err := LoadConfig(filepath.Join(dir, "app.toml")); err == nil || !strings.Contains(err.Error(), `unknown extension ".toml"`) {
+		t.Errorf("toml: got error %v", err)
+	}
+	if _, err := LoadConfig(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("missing file: no error")
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	cfg, err := ParseConfig(nil, FormatYAML)
+	if err != nil || !reflect.DeepEqual(cfg, Config{}) {
+		t.Errorf("empty yaml: got %+v, %v", cfg, err)
+	}
+	if _, err := ParseConfig([]byte(`{}`), "toml"); err == nil {
+		t.Error("toml format: no error")
+	}
+
+	tests := []struct {
+		name    string
+		data    string
+		format  string
+		wantErr []string
+	}{
+		{
+			name:    "json unknown key",
+			data:    `{"level": "info", "colour": true}`,
+			format:  FormatJSON,
+			wantErr: []string{`parsing json config: json: unknown field "colour"`},
+		},
+		{
+			name:    "yaml unknown key",
+			data:    "level: info\ncolour: true\n",
+			format:  FormatYAML,
+			wantErr: []string{`parsing yaml config: line 2: unknown key "colour"`},
+		},
+		{
+			name:    "yaml nested unknown key",
+			data:    "sampling:\n  initial: 1\n  every: 2\n",
+			format:  FormatYAML,
+			wantErr: []string{`parsing yaml config: line 3: unknown key "every"`},
+		},
+		{
+			name:   "invalid values",
+			data:   "mode: staging\nlevel: verbose\nsampling:\n  initial: 1\n  tick: soon\n",
+			format: FormatYAML,
+			wantErr: []string{
+				`mode: unknown mode "staging", use dev or prod`,
+				`level: unknown log level "verbose"`,
+				`sampling.tick: time: invalid duration "soon"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		_, err := ParseConfig([]byte(tt.data), tt.format)
+		errs := multierr.Errors(err)
+		if len(errs) != len(tt.wantErr) {
+			t.Errorf("%s: got errors %v, want %q", tt.name, errs, tt.wantErr)
+			continue
+		}
+		for i, want := range tt.wantErr {
+			if errs[i].Error() != want {
+				t.Errorf("%s: error %d: got %q, want %q", tt.name, i, errs[i], want)
+			}
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package logger
+
+import "context"
+
+type loggerKeyType struct{}
+
+var loggerKey loggerKeyType
+
+// NewContextWithLogger returns a new Context that carries the logger,
+// usually a request scoped one.
+func NewContextWithLogger(parent context.Context, l Logger) context.Context {
+	return context.WithValue(parent, loggerKey, l)
+}
+
+// FromContextLogger returns the logger stored in ctx, the zero-value
+// Logger discarding the entries if none.
+func FromContextLogger(ctx context.Context) Logger {
+	if ctx == nil {
+		return Logger{}
+	}
+	l, _ := ctx.Value(loggerKey).(Logger)
+	return l
+}
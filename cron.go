@@ -0,0 +1,49 @@
+package logger
+
+// CronLogger adapts a Logger to the robfig/cron v3 cron.Logger interface:
+//
+//	c := cron.New(cron.WithLogger(logger.NewCronLogger(lg)))
+//
+// The entries have a "logger" field with the "cron" value, and a "job"
+// field with the entry id when cron provides it.
+type CronLogger struct {
+	l         Logger
+	infoLevel Level
+}
+
+// NewCronLogger returns a CronLogger writing through l, reporting the
+// caller of its methods. The cron info messages, about scheduling, are
+// logged as debug, see WithInfoLevel.
+func NewCronLogger(l Logger) CronLogger {
+	return CronLogger{l: l.Named("cron").WithCallerSkip(1), infoLevel: DebugLevel}
+}
+
+// WithInfoLevel returns a copy of the CronLogger logging
+// the cron info messages with the given level.
+func (c CronLogger) WithInfoLevel(level Level) CronLogger {
+	c.infoLevel = level
+	return c
+}
+
+// Info logs routine messages about cron's operation.
+func (c CronLogger) Info(msg string, keysAndValues ...interface{}) {
+	c.with(keysAndValues).Log(c.infoLevel, msg)
+}
+
+// Error logs an error condition.
+func (c CronLogger) Error(err error, msg string, keysAndValues ...interface{}) {
+	c.with(keysAndValues).WithError(err).Error(msg)
+}
+
+// with returns the logger with the cron fields,
+// adding the job field from the cron entry id.
+func (c CronLogger) with(keysAndValues []interface{}) Logger {
+	fields := keysAndValues
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		if keysAndValues[i] == "entry" {
+			fields = append(fields[:len(fields):len(fields)], "job", keysAndValues[i+1])
+			break
+		}
+	}
+	return c.l.With(fields...)
+}
@@ -0,0 +1,43 @@
+package logger_test
+
+import (
+	"errors"
+	"testing"
+
+	logger "github.com/Aibier/go-logger"
+)
+
+func TestCronLogger(t *testing.T) {
+	rec := logger.NewRecorder()
+	cl := logger.NewCronLogger(logger.NewWithWriter(logger.Config{}, rec))
+	cl.Info("schedule", "now", "12:00", "entry", 3)
+	cl.WithInfoLevel(logger.InfoLevel).Info("start")
+	cl.Error(errors.New("boom"), "panic", "entry", 4)
+
+	entries := rec.Entries()
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries:\n%s", len(entries), rec.Dump())
+	}
+	for i, want := range []logger.Level{logger.DebugLevel, logger.InfoLevel, logger.ErrorLevel} {
+		if entries[i].Level != want {
+			t.Errorf("entry %d: got level %v, want %v", i, entries[i].Level, want)
+		}
+		if v, _ := entries[i].Field(logger.NameFieldKey); v != "cron" {
+			t.Errorf("entry %d: got logger %v", i, v)
+		}
+	}
+	if v, _ := entries[0].Field("job"); v != 3 {
+		t.Errorf("got job %v, want the entry id", v)
+	}
+	if v, _ := entries[2].Field("job"); v != 4 {
+		t.Errorf("got job %v, want the entry id", v)
+	}
+}
+
+func TestCronLoggerCaller(t *testing.T) {
+	lg, entries := newJSONLogger(t)
+	cl := logger.NewCronLogger(lg).WithInfoLevel(logger.InfoLevel)
+	cl.Info("start")
+	cl.Error(errors.New("boom"), "panic")
+	checkCallers(t, entries(), 2, "cron_test.go")
+}
@@ -0,0 +1,65 @@
+package logger
+
+import "strings"
+
+// datadogPrefix starts the dd-trace-go messages, followed by
+// the tracer version and the level, e.g.
+// "Datadog Tracer v1.62.0 WARN: ...".
+const datadogPrefix = "Datadog Tracer "
+
+// datadogLevels maps the level prefixes of the dd-trace-go messages.
+var datadogLevels = []struct {
+	prefix string
+	level  Level
+}{
+	{"ERROR: ", ErrorLevel},
+	{"WARN: ", WarningLevel},
+	{"INFO: ", InfoLevel},
+	{"DEBUG: ", DebugLevel},
+}
+
+// DatadogLogger adapts a Logger to the dd-trace-go ddtrace.Logger
+// interface:
+//
+//	tracer.Start(tracer.WithLogger(logger.NewDatadogLogger(lg)))
+//
+// The entries have a "logger" field with the "ddtrace" value, and a
+// "tracer_version" field when the message holds it.
+type DatadogLogger struct {
+	l Logger
+}
+
+// NewDatadogLogger returns a DatadogLogger writing through l,
+// reporting the caller of Log.
+func NewDatadogLogger(l Logger) DatadogLogger {
+	return DatadogLogger{l: l.Named("ddtrace").WithCallerSkip(1)}
+}
+
+// Log logs a dd-trace-go message, with the level of its prefix, info
+// by default. The tracer and level prefixes are stripped.
+func (d DatadogLogger) Log(msg string) {
+	l := d.l
+	level, msg, version := parseDatadogMessage(msg)
+	if version != "" {
+		l = l.With("tracer_version", version)
+	}
+	l.Log(level, msg)
+}
+
+// parseDatadogMessage returns the level, the message
+// and the tracer version of a dd-trace-go message.
+func parseDatadogMessage(msg string) (Level, string, string) {
+	msg = strings.TrimRight(msg, "\n")
+	var version string
+	if rest := strings.TrimPrefix(msg, datadogPrefix); rest != msg {
+		if i := strings.IndexByte(rest, ' '); i > 0 && rest[0] == 'v' {
+			version, msg = rest[:i], rest[i+1:]
+		}
+	}
+	for _, p := range datadogLevels {
+		if strings.HasPrefix(msg, p.prefix) {
+			return p.level, msg[len(p.prefix):], version
+		}
+	}
+	return InfoLevel, msg, version
+}
@@ -0,0 +1,45 @@
+package logger_test
+
+import (
+	"testing"
+
+	logger "github.com/Aibier/go-logger"
+)
+
+func TestDatadogLogger(t *testing.T) {
+	rec := logger.NewRecorder()
+	dl := logger.NewDatadogLogger(logger.NewWithWriter(logger.Config{Level: logger.DebugLevel}, rec))
+	tests := []struct {
+		msg, want, version string
+		level              logger.Level
+	}{
+		{"Datadog Tracer v1.62.0 WARN: agent unreachable\n", "agent unreachable", "v1.62.0", logger.WarningLevel},
+		{"Datadog Tracer v1.62.0 ERROR: lost 3 traces", "lost 3 traces", "v1.62.0", logger.ErrorLevel},
+		{"DEBUG: flushing", "flushing", "", logger.DebugLevel},
+		{"Datadog Tracer v1.62.0 INFO: started", "started", "v1.62.0", logger.InfoLevel},
+		{"no level", "no level", "", logger.InfoLevel},
+	}
+	for _, tt := range tests {
+		rec.Reset()
+		dl.Log(tt.msg)
+		e, ok := rec.LastEntry()
+		if !ok || e.Message() != tt.want || e.Level != tt.level {
+			t.Errorf("%q: got entry %v, want %v %q", tt.msg, e, tt.level, tt.want)
+			continue
+		}
+		if v, _ := e.Field(logger.NameFieldKey); v != "ddtrace" {
+			t.Errorf("%q: got logger %v", tt.msg, v)
+		}
+		if v, ok := e.Field("tracer_version"); tt.version != "" && v != tt.version || tt.version == "" && ok {
+			t.Errorf("%q: got tracer_version %v, want %q", tt.msg, v, tt.version)
+		}
+	}
+}
+
+func TestDatadogLoggerCaller(t *testing.T) {
+	lg, entries := newJSONLogger(t)
+	dl := logger.NewDatadogLogger(lg)
+	dl.Log("Datadog Tracer v1.62.0 WARN: agent unreachable")
+	dl.Log("started")
+	checkCallers(t, entries(), 2, "datadog_test.go")
+}
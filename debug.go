@@ -0,0 +1,220 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RecentErrorsSize is the number of error, panic and fatal
+// entries kept for the DebugHandler.
+const RecentErrorsSize = 20
+
+// recentCallersDepth is the number of frames kept to
+// resolve the caller of a recent error entry.
+const recentCallersDepth = 16
+
+// debugState holds the state of a logger, and of the loggers derived
+// from it, exposed by the DebugHandler.
+type debugState struct {
+	mode    string
+	outputs []string
+	clock   Clock
+	masker  *Masker
+	counts  [FatalLevel + 1]atomic.Uint64
+
+	mu     sync.Mutex
+	recent [RecentErrorsSize]recentEntry
+	next   int
+	n      int
+}
+
+// recentEntry is a kept error entry, its message is masked
+// and its caller resolved when the DebugState is read.
+type recentEntry struct {
+	level Level
+	time  time.Time
+	name  string
+	msg   string
+	pcs   [recentCallersDepth]uintptr
+	npc   int
+}
+
+// RecentEntry is an error entry reported by the DebugHandler,
+// without its fields but the logger name, see Logger.Named.
+// The message is masked using the Config Masker, whose Stats
+// and OnRedact callback are not affected.
+type RecentEntry struct {
+	Level   string    `json:"level"`
+	Time    time.Time `json:"time"`
+	Logger  string    `json:"logger,omitempty"`
+	Message string    `json:"msg"`
+	Caller  string    `json:"caller"`
+}
+
+// DebugState is the JSON document served by the DebugHandler.
+type DebugState struct {
+	Name         string            `json:"name,omitempty"`
+	Level        string            `json:"level"`
+	Mode         string            `json:"mode"`
+	Outputs      []string          `json:"outputs"`
+	Counts       map[string]uint64 `json:"counts"`
+	Middlewares  []string          `json:"middlewares"`
+	RecentErrors []RecentEntry     `json:"recent_errors"`
+}
+
+func newDebugState(cfg Config) *debugState {
+	d := &debugState{
+		mode:    string(cfg.mode()),
+		outputs: append([]string(nil), cfg.outputPaths(cfg.OutputPaths)...),
+		clock:   cfg.Clock,
+		masker:  cfg.masker().detached(),
+	}
+	return d
+}
+
+// record counts an entry, keeping the error ones. The message
+// is only built for them, and masked when read.
+func (d *debugState) record(level Level, name string, msg message) {
+	if d == nil {
+		return
+	}
+	if !level.valid() {
+		level = InfoLevel
+	}
+	d.counts[level].Add(1)
+	if level < ErrorLevel {
+		return
+	}
+	e := recentEntry{
+		level: level,
+		time:  d.now(),
+		name:  name,
+		msg:   msg.String(),
+	}
+	e.npc = runtime.Callers(2, e.pcs[:])
+	d.mu.Lock()
+	d.recent[d.next] = e
+	d.next = (d.next + 1) % RecentErrorsSize
+	if d.n < RecentErrorsSize {
+		d.n++
+	}
+	d.mu.Unlock()
+}
+
+// now returns the time of the Config Clock, the system one by default.
+func (d *debugState) now() time.Time {
+	if d.clock == nil {
+		return time.Now()
+	}
+	return d.clock.Now()
+}
+
+// formatMessage returns the message of a printf compatible
+// entry, str verbatim without args.
+func formatMessage(str string, args []interface{}) string {
+	if len(args) == 0 {
+		return str
+	}
+	return fmt.Sprintf(str, args...)
+}
+
+// recentErrors returns the kept error entries, the oldest first.
+func (d *debugState) recentErrors() []RecentEntry {
+	d.mu.Lock()
+	kept := make([]recentEntry, 0, d.n)
+	for i := d.n; i > 0; i-- {
+		kept = append(kept, d.recent[(d.next-i+RecentErrorsSize)%RecentErrorsSize])
+	}
+	d.mu.Unlock()
+	out := make([]RecentEntry, 0, len(kept))
+	for _, e := range kept {
+		out = append(out, RecentEntry{
+			Level:   e.level.String(),
+			Time:    e.time,
+			Logger:  e.name,
+			Message: d.masker.MaskString(e.msg),
+			Caller:  recentCaller(e.pcs[:e.npc]),
+		})
+	}
+	return out
+}
+
+// recentCaller returns the location of the first frame
+// of pcs outside of this package.
+func recentCaller(pcs []uintptr) string {
+	frames := runtime.CallersFrames(pcs)
+	for {
+		f, more := frames.Next()
+		if f.PC != 0 && !strings.HasPrefix(f.Function, "github.com/Aibier/go-logger.") {
+			return fmt.Sprintf("%s:%d", f.File, f.Line)
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
+
+// DebugState returns the current state of the logger: its name,
+// level, mode and outputs, the number of entries logged per level and the
+// last RecentErrorsSize error entries of the logger and of the loggers
+// derived from it.
+func (l Logger) DebugState() DebugState {
+	s := DebugState{
+		Name:         l.name,
+		Level:        DebugLevel.String(),
+		Counts:       make(map[string]uint64, len(levelNames)),
+		Middlewares:  []string{},
+		RecentErrors: []RecentEntry{},
+	}
+	if l.level != nil {
+		s.Level = l.level.get().String()
+	}
+	for _, m := range l.ctxMiddlewares {
+		s.Middlewares = append(s.Middlewares, funcName(m))
+	}
+	for _, name := range levelNames {
+		s.Counts[name] = 0
+	}
+	if d := l.debug; d != nil {
+		s.Mode = d.mode
+		s.Outputs = d.outputs
+		for i := range d.counts {
+			s.Counts[Level(i).String()] = d.counts[i].Load()
+		}
+		s.RecentErrors = d.recentErrors()
+	}
+	return s
+}
+
+// DebugHandler returns a handler serving the DebugState as JSON:
+//
+//	mux.Handle("/debug/logger", lg.DebugHandler())
+func (l Logger) DebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(l.DebugState()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// funcName returns the name of a function.
+func funcName(fn interface{}) string {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return fmt.Sprint(fn)
+	}
+	if f := runtime.FuncForPC(v.Pointer()); f != nil {
+		return f.Name()
+	}
+	return "unknown"
+}
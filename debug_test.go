@@ -0,0 +1,124 @@
+package logger_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	logger "github.com/Aibier/go-logger"
+)
+
+// getDebugState returns the DebugState served by the handler.
+func getDebugState(t *testing.T, h http.Handler) logger.DebugState {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("got content type %q", ct)
+	}
+	var s logger.DebugState
+	if err := json.NewDecoder(resp.Body).Decode(&s); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestDebugHandler(t *testing.T) {
+	ts := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	lg := logger.NewWithWriter(logger.Config{Level: logger.InfoLevel, Clock: logger.NewManualClock(ts)}, logger.NewRecorder())
+	lg.Debug("hidden")
+	lg.Info("info")
+	lg.Named("db").Errorf("query failed: Bearer %s", "hunter22token")
+	lg.Warnw("slow", "k", 1)
+
+	s := getDebugState(t, lg.DebugHandler())
+	if s.Name != "" || s.Level != "info" {
+		t.Errorf("got name %q, level %q", s.Name, s.Level)
+	}
+	for level, want := range map[string]uint64{"debug": 0, "info": 1, "warning": 1, "error": 1, "fatal": 0} {
+		if s.Counts[level] != want {
+			t.Errorf("%s: got count %d, want %d", level, s.Counts[level], want)
+		}
+	}
+	if len(s.RecentErrors) != 1 {
+		t.Fatalf("got recent errors %v", s.RecentErrors)
+	}
+	e := s.RecentErrors[0]
+	if e.Logger != "db" || e.Level != "error" || !e.Time.Equal(ts) {
+		t.Errorf("got recent error %+v", e)
+	}
+	if strings.Contains(e.Message, "hunter22token") || !strings.HasPrefix(e.Message, "query failed") {
+		t.Errorf("got message %q, want it masked", e.Message)
+	}
+	if !strings.Contains(e.Caller, "debug_test.go:") {
+		t.Errorf("got caller %q, want the test file", e.Caller)
+	}
+
+	if s := getDebugState(t, lg.Named("db").DebugHandler()); s.Name != "db" || s.Counts["info"] != 1 {
+		t.Errorf("got named state %+v, want the name and the shared counts", s)
+	}
+}
+
+func TestDebugStateRecentErrors(t *testing.T) {
+	lg := logger.NewWithWriter(logger.Config{}, logger.NewRecorder())
+	n := logger.RecentErrorsSize + 5
+	for i := 0; i < n; i++ {
+		lg.Errorf("error %d", i)
+	}
+	s := lg.DebugState()
+	if len(s.RecentErrors) != logger.RecentErrorsSize {
+		t.Fatalf("got %d recent errors", len(s.RecentErrors))
+	}
+	for i, e := range s.RecentErrors {
+		if want := fmt.Sprintf("error %d", i+5); e.Message != want {
+			t.Errorf("recent error %d: got %q, want %q", i, e.Message, want)
+		}
+	}
+	if s.Counts["error"] != uint64(n) {
+		t.Errorf("got error count %d", s.Counts["error"])
+	}
+
+	var zero logger.Logger
+	if s := zero.DebugState(); len(s.RecentErrors) != 0 || s.Counts["info"] != 0 {
+		t.Errorf("got state %+v for the zero Logger", s)
+	}
+}
+
+func TestDebugStateMaskerStats(t *testing.T) {
+	var redacted []string
+	m := logger.NewMasker(logger.DefaultMasker.Rules()...).WithOnRedact(func(rule string) {
+		redacted = append(redacted, rule)
+	})
+	lg := logger.NewWithWriter(logger.Config{Masker: m}, logger.NewRecorder())
+	lg.Errorf("query failed: Bearer %s", "hunter22token")
+
+	s := lg.DebugState()
+	if len(s.RecentErrors) != 1 || strings.Contains(s.RecentErrors[0].Message, "hunter22token") {
+		t.Fatalf("got recent errors %v, want the message masked", s.RecentErrors)
+	}
+	for rule, n := range m.Stats() {
+		if n != 0 {
+			t.Errorf("%s: got %d replacements, want the stats untouched", rule, n)
+		}
+	}
+	if len(redacted) != 0 {
+		t.Errorf("got OnRedact calls %v", redacted)
+	}
+}
+
+func BenchmarkDebugStateRecord(b *testing.B) {
+	lg := logger.NewNoOpLogger()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		lg.Infof("request %d", i)
+	}
+}
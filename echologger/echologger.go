@@ -0,0 +1,141 @@
+// Package echologger provides an Echo middleware logging the requests
+// with the github.com/Aibier/go-logger Logger.
+package echologger
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	logger "github.com/Aibier/go-logger"
+	"github.com/labstack/echo/v4"
+)
+
+type config struct {
+	skipPaths map[string]bool
+	headers   []string
+	masker    *logger.Masker
+}
+
+// EchoOption configures the EchoMiddleware.
+type EchoOption func(*config)
+
+// WithSkipPaths disables the access logs of the given route
+// patterns or paths, e.g. "/health".
+func WithSkipPaths(paths ...string) EchoOption {
+	return func(c *config) {
+		for _, p := range paths {
+			c.skipPaths[p] = true
+		}
+	}
+}
+
+// WithHeaders adds the given request headers to the access logs,
+// masked using the header masker.
+func WithHeaders(names ...string) EchoOption {
+	return func(c *config) {
+		c.headers = append(c.headers, names...)
+	}
+}
+
+// WithHeaderMasker sets the masker of the logged headers,
+// logger.DefaultMasker by default.
+func WithHeaderMasker(m *logger.Masker) EchoOption {
+	return func(c *config) {
+		c.masker = m
+	}
+}
+
+// EchoMiddleware returns a middleware storing a request scoped logger in
+// the request context, see logger.FromContextLogger, and logging an access
+// entry per request.
+// The request id is read from the X-Request-ID header when the request
+// context doesn't have one.
+// The entries are logged as errors for 5xx status codes, including the
+// ones of the echo.HTTPError returned by the handlers, as warnings for
+// 4xx ones and as info otherwise.
+func EchoMiddleware(l logger.Logger, opts ...EchoOption) echo.MiddlewareFunc {
+	cfg := config{
+		skipPaths: map[string]bool{},
+		masker:    logger.DefaultMasker,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			req := c.Request()
+			ctx := req.Context()
+			if id := req.Header.Get(echo.HeaderXRequestID); id != "" && logger.FromContext(ctx) == "" {
+				ctx = logger.NewContext(ctx, id)
+			}
+			reqLogger := l.WithContext(ctx)
+			c.SetRequest(req.WithContext(logger.NewContextWithLogger(ctx, reqLogger)))
+
+			err := next(c)
+			if err != nil {
+				// let the error handler write the response, so the status is known
+				c.Error(err)
+			}
+
+			route := c.Path()
+			if cfg.skipPaths[route] || cfg.skipPaths[req.URL.Path] {
+				return nil
+			}
+			if route == "" {
+				route = "unmatched"
+			}
+			status := c.Response().Status
+			var he *echo.HTTPError
+			if errors.As(err, &he) {
+				status = he.Code
+			}
+			fields := []interface{}{
+				"method", req.Method,
+				"route", route,
+				"status", status,
+				"latency", time.Since(start),
+				"bytes_out", c.Response().Size,
+				"client_ip", c.RealIP(),
+			}
+			if len(cfg.headers) > 0 {
+				fields = append(fields, "headers", maskHeaders(req.Header, cfg.headers, cfg.masker))
+			}
+			if err != nil {
+				fields = append(fields, "error", err)
+			}
+			reqLogger.With(fields...).Log(statusLevel(status), "request completed")
+			return nil
+		}
+	}
+}
+
+// statusLevel returns the level of the access entries.
+func statusLevel(status int) logger.Level {
+	switch {
+	case status >= http.StatusInternalServerError:
+		return logger.ErrorLevel
+	case status >= http.StatusBadRequest:
+		return logger.WarningLevel
+	default:
+		return logger.InfoLevel
+	}
+}
+
+// maskHeaders returns the values of the given headers masked as
+// header lines, so the masker header rules apply.
+func maskHeaders(h http.Header, names []string, m *logger.Masker) map[string]string {
+	out := make(map[string]string, len(names))
+	for _, name := range names {
+		v := h.Get(name)
+		if v == "" {
+			continue
+		}
+		prefix := http.CanonicalHeaderKey(name) + ": "
+		out[name] = strings.TrimPrefix(m.MaskString(prefix+v), prefix)
+	}
+	return out
+}
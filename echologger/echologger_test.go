@@ -0,0 +1,87 @@
+package echologger
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	logger "github.com/Aibier/go-logger"
+	"github.com/labstack/echo/v4"
+)
+
+func serve(e *echo.Echo, path string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	return w
+}
+
+func TestEchoMiddleware(t *testing.T) {
+	rec := logger.NewRecorder()
+	lg := logger.NewWithWriter(logger.Config{}, rec)
+	e := echo.New()
+	e.Use(EchoMiddleware(lg, WithSkipPaths("/health"), WithHeaders("Authorization")))
+	e.GET("/users/:id", func(c echo.Context) error {
+		logger.FromContextLogger(c.Request().Context()).Info("handler")
+		return c.String(http.StatusOK, "user")
+	})
+	e.GET("/forbidden", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusForbidden, "no access")
+	})
+	e.GET("/failure", func(c echo.Context) error {
+		return errors.New("database down")
+	})
+	e.GET("/health", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+
+	serve(e, "/users/42", map[string]string{
+		"Authorization":       "Bearer abcdefghijkl",
+		echo.HeaderXRequestID: "req-1",
+	})
+	serve(e, "/forbidden", nil)
+	serve(e, "/failure", nil)
+	serve(e, "/health", nil)
+
+	entries := rec.Entries()
+	if len(entries) != 4 {
+		t.Fatalf("got %d entries, want the handler one and 3 access ones:\n%s", len(entries), rec.Dump())
+	}
+	for _, e := range entries[:2] {
+		if v, _ := e.Field("request_id"); v != "req-1" {
+			t.Errorf("%s: got request id %v", e.Message(), v)
+		}
+	}
+
+	tests := []struct {
+		entry  logger.LogEntry
+		route  string
+		status int
+		level  logger.Level
+	}{
+		{entries[1], "/users/:id", http.StatusOK, logger.InfoLevel},
+		{entries[2], "/forbidden", http.StatusForbidden, logger.WarningLevel},
+		{entries[3], "/failure", http.StatusInternalServerError, logger.ErrorLevel},
+	}
+	for _, tt := range tests {
+		route, _ := tt.entry.Field("route")
+		status, _ := tt.entry.Field("status")
+		if route != tt.route || status != tt.status || tt.entry.Level != tt.level {
+			t.Errorf("got a %s entry for route %v with status %v, want a %s one for %s with %d",
+				tt.entry.Level, route, status, tt.level, tt.route, tt.status)
+		}
+		if _, ok := tt.entry.Field("latency"); !ok {
+			t.Errorf("%s: missing latency", tt.route)
+		}
+	}
+	if v, _ := entries[1].Field("bytes_out"); v != int64(len("user")) {
+		t.Errorf("got bytes_out %#v", v)
+	}
+	if headers, _ := entries[1].Field("headers"); strings.Contains(fmt.Sprint(headers), "abcdefghijkl") {
+		t.Errorf("the authorization header was not masked: %v", headers)
+	}
+}
@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"go.uber.org/multierr"
+)
+
+// Environment variables read by ConfigFromEnv.
+const (
+	// LevelEnv the minimum level, see ParseLevel. Debug when unset.
+	LevelEnv = "LOG_LEVEL"
+	// ModeEnv "dev" or "development" for ModeDevelopment,
+	// "prod" or "production" for the default one.
+	ModeEnv = "LOG_MODE"
+	// OutputsEnv the comma separated output paths, stdout when unset.
+	OutputsEnv = "LOG_OUTPUTS"
+	// DisableStacktraceEnv a boolean disabling the stack traces.
+	DisableStacktraceEnv = "LOG_DISABLE_STACKTRACE"
+)
+
+// ConfigFromEnv returns the Config read from the LevelEnv, ModeEnv,
+// OutputsEnv and DisableStacktraceEnv environment variables. The
+// unset or empty ones keep their default. An error naming every
+// invalid variable is returned.
+func ConfigFromEnv() (Config, error) {
+	var cfg Config
+	var errs error
+	if v := os.Getenv(LevelEnv); v != "" {
+		level, err := ParseLevel(v)
+		if err != nil {
+			errs = multierr.Append(errs, fmt.Errorf("%s: %w", LevelEnv, err))
+		}
+		cfg.Level = level
+	}
+	mode, err := parseMode(os.Getenv(ModeEnv))
+	if err != nil {
+		errs = multierr.Append(errs, fmt.Errorf("%s: %w", ModeEnv, err))
+	}
+	cfg.Mode = mode
+	if v := os.Getenv(OutputsEnv); v != "" {
+		for _, p := range strings.Split(v, ",") {
+			p = strings.TrimSpace(p)
+			if err := validateOutputPath(p); err != nil {
+				errs = multierr.Append(errs, fmt.Errorf("%s: output %q: %w", OutputsEnv, p, err))
+				continue
+			}
+			cfg.OutputPaths = append(cfg.OutputPaths, p)
+		}
+	}
+	if v := os.Getenv(DisableStacktraceEnv); v != "" {
+		disable, err := strconv.ParseBool(v)
+		if err != nil {
+			errs = multierr.Append(errs, fmt.Errorf("%s: invalid boolean %q", DisableStacktraceEnv, v))
+		}
+		cfg.DisableStacktrace = disable
+	}
+	return cfg, errs
+}
+
+// NewFromEnv returns a new logger with the Config read by ConfigFromEnv:
+//
+//	lg := logger.Must(logger.NewFromEnv())
+func NewFromEnv() (Logger, error) {
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		return Logger{}, err
+	}
+	return New(cfg)
+}
@@ -0,0 +1,114 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.uber.org/multierr"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "app.log")
+	missing := filepath.Join(dir, "missing", "app.log")
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    Config
+		wantErr []string
+	}{
+		{
+			name: "unset",
+			want: Config{},
+		},
+		{
+			name: "all set",
+			env: map[string]string{
+				LevelEnv:             " Warn ",
+				ModeEnv:              "Dev",
+				OutputsEnv:           "stdout, " + out,
+				DisableStacktraceEnv: "true",
+			},
+			want: Config{
+				Level:             WarningLevel,
+				Mode:              ModeDevelopment,
+				OutputPaths:       []string{"stdout", out},
+				DisableStacktrace: true,
+			},
+		},
+		{
+			name: "production",
+			env:  map[string]string{LevelEnv: "3", ModeEnv: "production", DisableStacktraceEnv: "0"},
+			want: Config{Level: ErrorLevel, Mode: ModeProduction},
+		},
+		{
+			name:    "unknown level",
+			env:     map[string]string{LevelEnv: "verbose"},
+			wantErr: []string{`LOG_LEVEL: unknown log level "verbose"`},
+		},
+		{
+			name: "every variable invalid",
+			env: map[string]string{
+				LevelEnv:             "9",
+				ModeEnv:              "staging",
+				OutputsEnv:           "stderr," + missing,
+				DisableStacktraceEnv: "maybe",
+			},
+			wantErr: []string{
+				`LOG_LEVEL: log level "9" out of range 0-6`,
+				`LOG_MODE: unknown mode "staging", use dev or prod`,
+				`LOG_OUTPUTS: output "` + missing + `": directory ` + filepath.Dir(missing) + `: `,
+				`LOG_DISABLE_STACKTRACE: invalid boolean "maybe"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, k := range []string{LevelEnv, ModeEnv, OutputsEnv, DisableStacktraceEnv} {
+				t.Setenv(k, tt.env[k])
+			}
+			cfg, err := ConfigFromEnv()
+			errs := multierr.Errors(err)
+			if len(errs) != len(tt.wantErr) {
+				t.Fatalf("got errors %v, want %q", errs, tt.wantErr)
+			}
+			for i, want := range tt.wantErr {
+				if !strings.HasPrefix(errs[i].Error(), want) {
+					t.Errorf("error %d: got %q, want prefix %q", i, errs[i], want)
+				}
+			}
+			if err == nil && !reflect.DeepEqual(cfg, tt.want) {
+				t.Errorf("got config %+v, want %+v", cfg, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewFromEnv(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "app.log")
+	t.Setenv(LevelEnv, "info")
+	t.Setenv(ModeEnv, "")
+	t.Setenv(OutputsEnv, out)
+	t.Setenv(DisableStacktraceEnv, "")
+
+	lg := Must(NewFromEnv())
+	lg.Debug("dropped")
+	lg.Info("kept")
+	lg.Sync()
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(data); strings.Contains(s, "dropped") || !strings.Contains(s, `"msg":"kept"`) {
+		t.Errorf("got output %s", s)
+	}
+
+	t.Setenv(LevelEnv, "verbose")
+	if _, err := NewFromEnv(); err == nil || !strings.Contains(err.Error(), LevelEnv) {
+		t.Errorf("got error %v, want one naming %s", err, LevelEnv)
+	}
+}
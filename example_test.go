@@ -0,0 +1,50 @@
+package logger_test
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	logger "github.com/Aibier/go-logger"
+)
+
+func ExampleRecorder_Notify() {
+	rec := logger.NewRecorder()
+	lg := logger.NewWithWriter(logger.Config{}, rec)
+	entries := rec.Notify()
+
+	go func() {
+		lg.WithError(errors.New("connection reset")).Error("sync failed")
+	}()
+
+	select {
+	case e := <-entries:
+		fmt.Println(e.Level, e.Message())
+	case <-time.After(time.Second):
+		fmt.Println("no entry logged")
+	}
+	// Output: error sync failed
+}
+
+func ExampleRecorder_Flatten() {
+	rec := logger.NewRecorder()
+	rec.SetClock(logger.NewManualClock(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)))
+	lg := logger.NewWithWriter(logger.Config{}, rec)
+	lg.With("user_id", 42).Infow("user saved", "user_id", 43, "table", "users")
+
+	want := []map[string]interface{}{{
+		"level":   "info",
+		"msg":     "user saved",
+		"time":    time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+		"user_id": 43,
+		"table":   "users",
+	}}
+	if diff := cmp.Diff(want, rec.Flatten()); diff != "" {
+		fmt.Printf("unexpected entries (-want +got):\n%s", diff)
+		return
+	}
+	fmt.Println("entries match")
+	// Output: entries match
+}
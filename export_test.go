@@ -0,0 +1,11 @@
+package logger
+
+import "io"
+
+// SetMissingWriterOutput sets the output of the DebugMissingWriter
+// warnings, returning a function restoring it.
+func SetMissingWriterOutput(w io.Writer) func() {
+	prev := missingWriterOutput
+	missingWriterOutput = w
+	return func() { missingWriterOutput = prev }
+}
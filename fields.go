@@ -0,0 +1,298 @@
+package logger
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// RedactedValue replaces the values of the redacted fields.
+const RedactedValue = "[REDACTED]"
+
+// MissingValue is the value of the dangling keys of the field lists.
+const MissingValue = "(MISSING)"
+
+// DroppedFieldsKey is the field key counting the fields
+// dropped by the AllowedFieldKeys config.
+const DroppedFieldsKey = "dropped_fields"
+
+// builtinFieldKeys the keys always allowed by AllowedFieldKeys.
+var builtinFieldKeys = []string{"ts", "level", "msg", "caller", "stacktrace", "request_id"}
+
+// fieldProcessor transforms the fields added to a logger
+// before they reach the writer.
+type fieldProcessor struct {
+	allowKeys    []string
+	countDropped bool
+	redactKeys   []string
+	hashKeys     []string
+	hashSecret   []byte
+	masker       *Masker
+	strict       bool
+}
+
+// newFieldProcessor returns the field processor for the given config,
+// nil if no processing is needed.
+func newFieldProcessor(cfg Config) *fieldProcessor {
+	if len(cfg.RedactKeys) == 0 && len(cfg.HashKeys) == 0 && len(cfg.AllowedFieldKeys) == 0 &&
+		!cfg.MaskFieldValues && !cfg.StrictFields {
+		return nil
+	}
+	fp := &fieldProcessor{strict: cfg.StrictFields}
+	if len(cfg.AllowedFieldKeys) > 0 {
+		for _, k := range append(builtinFieldKeys, cfg.AllowedFieldKeys...) {
+			fp.allowKeys = append(fp.allowKeys, strings.ToLower(k))
+		}
+		fp.countDropped = cfg.CountDroppedFields
+	}
+	if cfg.MaskFieldValues {
+		fp.masker = cfg.masker()
+	}
+	for k, ok := range cfg.HashKeys {
+		if ok {
+			fp.hashKeys = append(fp.hashKeys, strings.ToLower(k))
+		}
+	}
+	if len(fp.hashKeys) > 0 {
+		fp.hashSecret = []byte(cfg.HashSecret)
+		if len(fp.hashSecret) == 0 {
+			fp.hashSecret = defaultHashSecret()
+		}
+	}
+	for _, k := range cfg.RedactKeys {
+		fp.redactKeys = append(fp.redactKeys, strings.ToLower(k))
+	}
+	return fp
+}
+
+// process returns the processed key/value pairs and the number of
+// fields dropped to count, the given slice is never modified.
+func (fp *fieldProcessor) process(fields []interface{}) ([]interface{}, int) {
+	if fp == nil || len(fields) == 0 {
+		return fields, 0
+	}
+	out, dropped := fp.allow(fields)
+	for i := 0; i+1 < len(out); i += 2 {
+		key, ok := out[i].(string)
+		if !ok {
+			continue
+		}
+		if fp.redacted(key) {
+			out[i+1] = RedactedValue
+			continue
+		}
+		if matchKey(fp.hashKeys, key) {
+			out[i+1] = hashValue(fp.hashSecret, out[i+1])
+			continue
+		}
+		out[i+1] = fp.mask(fp.redactMap(out[i+1]))
+	}
+	return out, dropped
+}
+
+// repairFields returns the fields with the non-string keys stringified
+// and a MissingValue added to a dangling key, and the description of
+// the problems found. The given slice is never modified.
+func repairFields(fields []interface{}) ([]interface{}, string) {
+	var problems []string
+	for i := 0; i < len(fields); i += 2 {
+		if _, ok := fields[i].(string); !ok {
+			problems = append(problems, fmt.Sprintf("non-string key %v", fields[i]))
+		}
+	}
+	dangling := len(fields)%2 == 1
+	if dangling {
+		problems = append(problems, fmt.Sprintf("dangling key %v", fields[len(fields)-1]))
+	}
+	if len(problems) == 0 {
+		return fields, ""
+	}
+
+	out := make([]interface{}, len(fields), len(fields)+1)
+	copy(out, fields)
+	for i := 0; i < len(out); i += 2 {
+		if _, ok := out[i].(string); !ok {
+			out[i] = fmt.Sprint(out[i])
+		}
+	}
+	if dangling {
+		out = append(out, MissingValue)
+	}
+	return out, strings.Join(problems, ", ")
+}
+
+// externalCaller returns the location of the first caller
+// outside of this package.
+func externalCaller() string {
+	f := externalFrame()
+	if f.PC == 0 {
+		return "unknown"
+	}
+	return fmt.Sprintf("%s:%d", f.File, f.Line)
+}
+
+// externalFrame returns the frame of the first caller outside
+// of this package, a zero frame if not found.
+func externalFrame() runtime.Frame {
+	pc := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pc[:runtime.Callers(3, pc)])
+	for {
+		f, more := frames.Next()
+		if !strings.HasPrefix(f.Function, "github.com/Aibier/go-logger.") {
+			return f
+		}
+		if !more {
+			return runtime.Frame{}
+		}
+	}
+}
+
+// allow returns a copy of the fields dropping the ones whose key is
+// not allowed, and their number when CountDroppedFields is set.
+func (fp *fieldProcessor) allow(fields []interface{}) ([]interface{}, int) {
+	if len(fp.allowKeys) == 0 {
+		out := make([]interface{}, len(fields))
+		copy(out, fields)
+		return out, 0
+	}
+	out := make([]interface{}, 0, len(fields))
+	var dropped int
+	for i := 0; i < len(fields); i += 2 {
+		key, ok := fields[i].(string)
+		if !ok || i+1 == len(fields) || !matchKey(fp.allowKeys, key) {
+			dropped++
+			continue
+		}
+		out = append(out, key, fields[i+1])
+	}
+	if !fp.countDropped {
+		dropped = 0
+	}
+	return out, dropped
+}
+
+// mask masquerades the secrets from string and []byte values.
+func (fp *fieldProcessor) mask(v interface{}) interface{} {
+	if fp.masker == nil {
+		return v
+	}
+	switch t := v.(type) {
+	case string:
+		return fp.masker.MaskString(t)
+	case []byte:
+		return fp.masker.Mask(t)
+	}
+	return v
+}
+
+// redacted returns if the values of the given key must be redacted.
+func (fp *fieldProcessor) redacted(key string) bool {
+	return matchKey(fp.redactKeys, key)
+}
+
+// matchKey returns if the key matches any of the lower case patterns.
+func matchKey(patterns []string, key string) bool {
+	if len(patterns) == 0 {
+		return false
+	}
+	key = strings.ToLower(key)
+	for _, p := range patterns {
+		if globMatch(p, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactMap redacts the keys of a map value, only one level deep.
+func (fp *fieldProcessor) redactMap(v interface{}) interface{} {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		var cp map[string]interface{}
+		for k := range m {
+			if !fp.redacted(k) {
+				continue
+			}
+			if cp == nil {
+				cp = make(map[string]interface{}, len(m))
+				for k, v := range m {
+					cp[k] = v
+				}
+			}
+			cp[k] = RedactedValue
+		}
+		if cp != nil {
+			return cp
+		}
+	case map[string]string:
+		var cp map[string]string
+		for k := range m {
+			if !fp.redacted(k) {
+				continue
+			}
+			if cp == nil {
+				cp = make(map[string]string, len(m))
+				for k, v := range m {
+					cp[k] = v
+				}
+			}
+			cp[k] = RedactedValue
+		}
+		if cp != nil {
+			return cp
+		}
+	}
+	return v
+}
+
+// mapFields returns the key/value pairs of the map, sorted by key,
+// without the empty keys.
+func mapFields(m map[string]interface{}) []interface{} {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		if k != "" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	fields := make([]interface{}, 0, 2*len(keys))
+	for _, k := range keys {
+		fields = append(fields, k, m[k])
+	}
+	return fields
+}
+
+// masker returns the masker to use, DefaultMasker by default.
+func (c Config) masker() *Masker {
+	m := c.Masker
+	if m == nil {
+		m = DefaultMasker
+	}
+	if c.HashSecret != "" {
+		m = m.WithHashSecret(c.HashSecret)
+	}
+	return m
+}
+
+// globMatch reports whether s matches the pattern,
+// where '*' matches any sequence of characters.
+func globMatch(pattern, s string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == s
+	}
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+	last := parts[len(parts)-1]
+	for _, p := range parts[1 : len(parts)-1] {
+		i := strings.Index(s, p)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(p):]
+	}
+	return len(s) >= len(last) && strings.HasSuffix(s, last)
+}
@@ -0,0 +1,109 @@
+package logger
+
+import (
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// iso8601TimeFormat the default format of the time.Time field
+// values, the ISO8601 format used by the zap encoders.
+const iso8601TimeFormat = "2006-01-02T15:04:05.000Z0700"
+
+// normalizeFields returns the fields with their values rendered the
+// same way for every writer, see normalizeValue.
+// The given slice is never modified.
+func normalizeFields(fields []interface{}, timeFormat, durationFormat string) []interface{} {
+	var out []interface{}
+	for i := 1; i < len(fields); i += 2 {
+		v, ok := normalizeValue(fields[i], timeFormat, durationFormat)
+		if !ok {
+			continue
+		}
+		if out == nil {
+			out = make([]interface{}, len(fields))
+			copy(out, fields)
+		}
+		out[i] = v
+	}
+	if out == nil {
+		return fields
+	}
+	return out
+}
+
+// normalizeValue renders errors, fmt.Stringers, times and durations
+// as the zap encoders do, the times with the given Config TimeFormat
+// and the durations with the given Config DurationFormat, and []byte
+// values as a string, base64 encoded if not valid UTF-8. It returns
+// false for the values left unchanged.
+func normalizeValue(v interface{}, timeFormat, durationFormat string) (interface{}, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return formatTime(t, timeFormat), true
+	case time.Duration:
+		return formatDuration(t, durationFormat), true
+	case error:
+		return safeString(v, t.Error), true
+	case fmt.Stringer:
+		return safeString(v, t.String), true
+	case []byte:
+		if utf8.Valid(t) {
+			return string(t), true
+		}
+		return base64.StdEncoding.EncodeToString(t), true
+	}
+	return v, false
+}
+
+// formatTime returns the value of a time in the given Config
+// TimeFormat, as encoded by the zap writer.
+func formatTime(t time.Time, format string) interface{} {
+	switch strings.ToLower(format) {
+	case "", "iso8601":
+		return t.Format(iso8601TimeFormat)
+	case "rfc3339":
+		return t.Format(time.RFC3339)
+	case "rfc3339nano":
+		return t.Format(time.RFC3339Nano)
+	case "epoch":
+		return float64(t.UnixNano()) / float64(time.Second)
+	case "epochmillis":
+		return t.UnixNano() / int64(time.Millisecond)
+	case "epochnanos":
+		return t.UnixNano()
+	}
+	return t.Format(format)
+}
+
+// formatDuration returns the value of a duration
+// in the given Config DurationFormat.
+func formatDuration(d time.Duration, format string) interface{} {
+	switch format {
+	case "seconds":
+		return d.Seconds()
+	case "nanos":
+		return int64(d)
+	case "string":
+		return d.String()
+	default:
+		return float64(d) / float64(time.Millisecond)
+	}
+}
+
+// safeString returns the result of fn, "<nil>" for nil pointer values
+// and a description of the panic if fn panics.
+func safeString(v interface{}, fn func() string) (s string) {
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return "<nil>"
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			s = fmt.Sprintf("<PANIC=%v>", r)
+		}
+	}()
+	return fn()
+}
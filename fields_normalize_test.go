@@ -0,0 +1,94 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testStringer is a fmt.Stringer field value.
+type testStringer struct{}
+
+func (testStringer) String() string { return "stringer" }
+
+// jsonFields returns the fields of a json entry, without its
+// timestamp, level and message, re-encoded with sorted keys.
+func jsonFields(t *testing.T, entry []byte) string {
+	dec := json.NewDecoder(bytes.NewReader(entry))
+	dec.UseNumber()
+	var fields map[string]interface{}
+	if err := dec.Decode(&fields); err != nil {
+		t.Fatalf("decoding %s: %v", entry, err)
+	}
+	for _, k := range []string{"ts", "level", "msg"} {
+		delete(fields, k)
+	}
+	b, err := json.Marshal(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestNormalizedFields(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 123456789, time.UTC)
+	fields := []interface{}{
+		"time", at,
+		"duration", 1500 * time.Millisecond,
+		"error", errors.New("failed"),
+		"stringer", testStringer{},
+		"text", []byte("text"),
+		"binary", []byte{0xff, 0xfe},
+	}
+
+	var got []string
+	for _, format := range []string{"", "rfc3339", "rfc3339nano", "epoch", "epochmillis", "epochnanos", "2006-01-02"} {
+		path, sink := newMemorySink(t)
+		cfg := Config{TimeFormat: format, OutputPaths: []string{path}, DisableInitialFields: true, DisableCaller: true}
+		zlg, err := New(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rec := NewRecorder()
+		rlg := NewWithWriter(cfg, rec)
+		for _, lg := range []Logger{zlg, rlg} {
+			lg.With(fields...).Info("with")
+			lg.Infow("infow", fields...)
+		}
+
+		lines := strings.Split(strings.TrimSpace(sink.String()), "\n")
+		for i, e := range rec.Entries() {
+			b, err := json.Marshal(e.FieldMap())
+			if err != nil {
+				t.Fatal(err)
+			}
+			zapFields, recFields := jsonFields(t, []byte(lines[i])), jsonFields(t, b)
+			if zapFields != recFields {
+				t.Errorf("time format %q, %s: zap fields %s, recorded fields %s", format, e.Message(), zapFields, recFields)
+			}
+			if i == 0 {
+				got = append(got, zapFields)
+			}
+		}
+	}
+
+	golden := filepath.Join("testdata", "normalized_fields.golden")
+	out := strings.Join(got, "\n") + "\n"
+	if os.Getenv(UpdateGoldenEnv) == "true" {
+		if err := os.WriteFile(golden, []byte(out), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != string(want) {
+		t.Errorf("fields differ:\n%s", lineDiff(golden, string(want), out))
+	}
+}
@@ -0,0 +1,34 @@
+package logger_test
+
+import (
+	"strings"
+	"testing"
+
+	logger "github.com/Aibier/go-logger"
+)
+
+func TestStrictFields(t *testing.T) {
+	rec := logger.NewRecorder()
+	lg := logger.NewWithWriter(logger.Config{StrictFields: true}, rec)
+	lg.With("user_id", 1, "orphan").Info("dangling")
+	lg.Infow("non-string", 42, "oops")
+
+	var errs []logger.LogEntry
+	for _, e := range rec.Entries() {
+		if e.Level == logger.ErrorLevel {
+			errs = append(errs, e)
+		}
+	}
+	if len(errs) != 2 {
+		t.Fatalf("got %d diagnostics, want 2:\n%s", len(errs), rec.Dump())
+	}
+	for i, want := range []string{"dangling key orphan", "non-string key 42"} {
+		msg := errs[i].Message()
+		if !strings.Contains(msg, "fields_strict_test.go:") || !strings.HasSuffix(msg, want) {
+			t.Errorf("got %q, want the caller and %q", msg, want)
+		}
+	}
+	if n := len(rec.Entries()); n != 4 {
+		t.Errorf("got %d entries, want the 2 repaired ones too", n)
+	}
+}
@@ -0,0 +1,202 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestMaskFieldValues(t *testing.T) {
+	rec := NewRecorder()
+	lg := NewWithWriter(Config{MaskFieldValues: true}, rec)
+	lg.With("details", "authorization: Bearer abcdefghijkl", "attempt", 3).
+		Infow("upstream call", "raw_response", []byte(`{"password":"hunter2secret"}`))
+
+	e, _ := rec.LastEntry()
+	tests := []struct {
+		key  string
+		want interface{}
+	}{
+		{"details", "authorization: Bearer abc*****jkl"},
+		{"raw_response", `{"password":"hu***t"}`},
+		{"attempt", 3},
+	}
+	for _, tt := range tests {
+		v, ok := e.Field(tt.key)
+		if b, isBytes := v.([]byte); isBytes {
+			v = string(b)
+		}
+		if !ok || v != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.key, v, tt.want)
+		}
+	}
+
+	NewWithWriter(Config{}, rec).With("details", "authorization: Bearer abcdefghijkl").Info("unmasked")
+	if e, _ := rec.LastEntry(); fmt.Sprint(e.FieldMap()["details"]) != "authorization: Bearer abcdefghijkl" {
+		t.Errorf("masked without MaskFieldValues: %v", e.Fields)
+	}
+}
+
+func BenchmarkWithMaskFieldValues(b *testing.B) {
+	fields := make([]interface{}, 0, 20)
+	for i := 0; i < 10; i++ {
+		fields = append(fields, fmt.Sprintf("key%d", i), "a clean string value")
+	}
+	for _, mask := range []bool{false, true} {
+		b.Run(fmt.Sprintf("MaskFieldValues=%v", mask), func(b *testing.B) {
+			lg := NewWithWriter(Config{MaskFieldValues: mask}, NewRecorder(WithCapacity(1)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				lg.With(fields...)
+			}
+		})
+	}
+}
+
+func TestMalformedFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields []interface{}
+		want   map[string]interface{}
+	}{
+		{"dangling key", []interface{}{"user_id", 1, "orphan"}, map[string]interface{}{"user_id": 1, "orphan": MissingValue}},
+		{"non-string key", []interface{}{42, "oops"}, map[string]interface{}{"42": "oops"}},
+		{"both", []interface{}{42, "oops", "orphan"}, map[string]interface{}{"42": "oops", "orphan": MissingValue}},
+	}
+	for _, tt := range tests {
+		path, sink := newMemorySink(t)
+		zlg, err := New(Config{OutputPaths: []string{path}, DisableInitialFields: true, DisableCaller: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		rec := NewRecorder()
+		rlg := NewWithWriter(Config{}, rec)
+		for _, lg := range []Logger{zlg, rlg} {
+			lg.With(tt.fields...).Info("with")
+			lg.Infow("infow", tt.fields...)
+		}
+
+		lines := strings.Split(strings.TrimSpace(sink.String()), "\n")
+		entries := rec.Entries()
+		if len(lines) != 2 || len(entries) != 2 {
+			t.Fatalf("%s: got %d zap and %d recorded entries:\n%s", tt.name, len(lines), len(entries), sink.String())
+		}
+		for i := range lines {
+			var zapFields map[string]interface{}
+			if err := json.Unmarshal([]byte(lines[i]), &zapFields); err != nil {
+				t.Fatal(err)
+			}
+			for _, k := range []string{"level", "ts", "msg"} {
+				delete(zapFields, k)
+			}
+			recFields := entries[i].FieldMap()
+			if fmt.Sprint(zapFields) != fmt.Sprint(tt.want) || fmt.Sprint(recFields) != fmt.Sprint(tt.want) {
+				t.Errorf("%s %s: got zap fields %v and recorded fields %v, want %v",
+					tt.name, entries[i].Message(), zapFields, recFields, tt.want)
+			}
+		}
+	}
+}
+
+func TestRedactKeys(t *testing.T) {
+	path, sink := newMemorySink(t)
+	middleware := func(context.Context) []interface{} { return []interface{}{"Session_Token", "s3cr3t-session"} }
+	cfg := Config{
+		OutputPaths:          []string{path},
+		DisableInitialFields: true,
+		RedactKeys:           []string{"password", "*_token"},
+		CtxMiddlewares:       []CtxMiddleware{middleware},
+	}
+	zl, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := NewRecorder()
+	for _, lg := range []Logger{zl, NewWithWriter(cfg, rec)} {
+		lg.With("Password", "hunter2").WithContext(context.Background()).Infow("login",
+			"user", "bob",
+			"refresh_token", "s3cr3t-refresh",
+			"request", map[string]interface{}{"password": "hunter3", "nested": map[string]string{"password": "kept"}},
+		)
+	}
+	zl.Sync()
+
+	var z map[string]interface{}
+	if err := json.Unmarshal([]byte(sink.String()), &z); err != nil {
+		t.Fatal(err)
+	}
+	e, _ := rec.LastEntry()
+	r := e.FieldMap()
+	for _, key := range []string{"Password", "Session_Token", "refresh_token"} {
+		if z[key] != RedactedValue || r[key] != RedactedValue {
+			t.Errorf("%s: got %v in zap and %v in the recorder, want it redacted", key, z[key], r[key])
+		}
+	}
+	if z["user"] != "bob" || r["user"] != "bob" {
+		t.Errorf("got user %v in zap and %v in the recorder", z["user"], r["user"])
+	}
+	zreq, _ := z["request"].(map[string]interface{})
+	rreq, _ := r["request"].(map[string]interface{})
+	if zreq["password"] != RedactedValue || rreq["password"] != RedactedValue {
+		t.Errorf("got request %v in zap and %v in the recorder, want its password redacted", zreq, rreq)
+	}
+	if fmt.Sprint(zreq["nested"]) != "map[password:kept]" || fmt.Sprint(rreq["nested"]) != "map[password:kept]" {
+		t.Errorf("got nested %v in zap and %v in the recorder, want one level redacted only", zreq["nested"], rreq["nested"])
+	}
+	if out := sink.String() + string(rec.Dump()); strings.Contains(out, "hunter") || strings.Contains(out, "s3cr3t") {
+		t.Errorf("got secrets logged:\n%s", out)
+	}
+}
+
+func TestAllowedFieldKeys(t *testing.T) {
+	userMiddleware := func(context.Context) []interface{} { return []interface{}{"user_id", 42, "email", "bob@example.com"} }
+	for _, count := range []bool{false, true} {
+		rec := NewRecorder()
+		lg := NewWithWriter(Config{
+			AllowedFieldKeys:   []string{"user_id", "http_*"},
+			CountDroppedFields: count,
+			CtxMiddlewares:     []CtxMiddleware{userMiddleware},
+		}, rec)
+		ctx := NewContext(context.Background(), "req-1")
+		derived := lg.With("HTTP_Method", "GET", "secret", "s1").WithContext(ctx)
+		derived.Infow("request", "http_status", 200, "body", "b1")
+		derived.Infof("request %s", "done")
+		lg.Info("clean")
+
+		tests := []struct {
+			fields  string
+			dropped interface{}
+		}{
+			{"[HTTP_Method GET user_id 42 request_id req-1 http_status 200]", 3},
+			{"[HTTP_Method GET user_id 42 request_id req-1]", 2},
+			{"[]", nil},
+		}
+		entries := rec.Entries()
+		if len(entries) != len(tests) {
+			t.Fatalf("got entries:\n%s", rec.Dump())
+		}
+		for i, tt := range tests {
+			fields := entries[i].allFields()
+			var dropped []interface{}
+			for j := 0; j+1 < len(fields); j += 2 {
+				if fields[j] == DroppedFieldsKey {
+					dropped = append(dropped, fields[j+1])
+					fields = append(fields[:j:j], fields[j+2:]...)
+					j -= 2
+				}
+			}
+			if fmt.Sprint(fields) != tt.fields {
+				t.Errorf("count %v, entry %d: got fields %v, want %s", count, i, fields, tt.fields)
+			}
+			want := []interface{}{tt.dropped}
+			if !count || tt.dropped == nil {
+				want = nil
+			}
+			if fmt.Sprint(dropped) != fmt.Sprint(want) {
+				t.Errorf("count %v, entry %d: got dropped fields %v, want %v", count, i, dropped, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package logger
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Flag names registered by RegisterFlags.
+const (
+	LevelFlag  = "log-level"
+	FormatFlag = "log-format"
+	OutputFlag = "log-output"
+	DevFlag    = "log-dev"
+)
+
+// RegisterFlags registers the logger flags on fs, writing into cfg
+// when parsed, the current cfg values being the defaults:
+//
+//	--log-level   the minimum level, e.g. "info"
+//	--log-format  "json" or "console", see Config.Encoding
+//	--log-output  an output path, repeatable, replacing the configured ones
+//	--log-dev     selects ModeDevelopment
+//
+// The values are validated when parsed. The writers only encode the
+// entries as json or console, so --log-format rejects the other formats,
+// e.g. logfmt. See NewFromFlags.
+func RegisterFlags(fs *flag.FlagSet, cfg *Config) {
+	fs.Var(&levelFlag{cfg: cfg}, LevelFlag, "minimum log level: debug, info, warning, error, dpanic, panic or fatal")
+	fs.Var(&formatFlag{cfg: cfg}, FormatFlag, "log format: json or console")
+	fs.Var(&outputFlag{cfg: cfg}, OutputFlag, "log output path, e.g. stdout or a file, may be repeated")
+	fs.Var(&devFlag{cfg: cfg}, DevFlag, "enable the development logging mode")
+}
+
+// NewFromFlags returns a new logger with the Config registered
+// on fs by RegisterFlags, once the flags are parsed:
+//
+//	var cfg logger.Config
+//	logger.RegisterFlags(flag.CommandLine, &cfg)
+//	flag.Parse()
+//	lg := logger.Must(logger.NewFromFlags(flag.CommandLine))
+func NewFromFlags(fs *flag.FlagSet) (Logger, error) {
+	cfg, err := flagsConfig(fs)
+	if err != nil {
+		return Logger{}, err
+	}
+	return New(*cfg)
+}
+
+// flagsConfig returns the Config registered on fs by RegisterFlags.
+func flagsConfig(fs *flag.FlagSet) (*Config, error) {
+	if f := fs.Lookup(LevelFlag); f != nil {
+		if v, ok := f.Value.(*levelFlag); ok {
+			return v.cfg, nil
+		}
+	}
+	return nil, errors.New("logger flags not registered, see RegisterFlags")
+}
+
+// levelFlag is the flag.Value of the LevelFlag.
+type levelFlag struct {
+	cfg *Config
+}
+
+func (f *levelFlag) String() string {
+	if f.cfg == nil {
+		return ""
+	}
+	return f.cfg.Level.String()
+}
+
+func (f *levelFlag) Set(s string) error {
+	return f.cfg.Level.Set(s)
+}
+
+// Type implements the pflag.Value interface.
+func (f *levelFlag) Type() string {
+	return "level"
+}
+
+// formatFlag is the flag.Value of the FormatFlag.
+type formatFlag struct {
+	cfg *Config
+}
+
+func (f *formatFlag) String() string {
+	if f.cfg == nil {
+		return ""
+	}
+	return f.cfg.Encoding
+}
+
+func (f *formatFlag) Set(s string) error {
+	switch format := strings.ToLower(strings.TrimSpace(s)); format {
+	case "json", "console":
+		f.cfg.Encoding = format
+	default:
+		return fmt.Errorf("unknown log format %q, use json or console", s)
+	}
+	return nil
+}
+
+// Type implements the pflag.Value interface.
+func (f *formatFlag) Type() string {
+	return "format"
+}
+
+// outputFlag is the flag.Value of the OutputFlag, the first
+// parsed value replaces the configured output paths.
+type outputFlag struct {
+	cfg *Config
+	set bool
+}
+
+func (f *outputFlag) String() string {
+	if f.cfg == nil {
+		return ""
+	}
+	return strings.Join(f.cfg.OutputPaths, ",")
+}
+
+func (f *outputFlag) Set(s string) error {
+	if err := validateOutputPath(s); err != nil {
+		return err
+	}
+	if !f.set {
+		f.cfg.OutputPaths = nil
+		f.set = true
+	}
+	f.cfg.OutputPaths = append(f.cfg.OutputPaths, s)
+	return nil
+}
+
+// Type implements the pflag.Value interface.
+func (f *outputFlag) Type() string {
+	return "path"
+}
+
+// devFlag is the boolean flag.Value of the DevFlag.
+type devFlag struct {
+	cfg *Config
+}
+
+func (f *devFlag) String() string {
+	return strconv.FormatBool(f.cfg != nil && f.cfg.development())
+}
+
+func (f *devFlag) Set(s string) error {
+	dev, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	f.cfg.Mode = ModeProduction
+	if dev {
+		f.cfg.Mode = ModeDevelopment
+	}
+	return nil
+}
+
+func (f *devFlag) IsBoolFlag() bool {
+	return true
+}
+
+// Type implements the pflag.Value interface.
+func (f *devFlag) Type() string {
+	return "bool"
+}
@@ -0,0 +1,94 @@
+package logger
+
+import (
+	"flag"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// parseFlags returns the Config registered on a FlagSet
+// parsing argv, starting from base.
+func parseFlags(base Config, argv ...string) (Config, *flag.FlagSet, error) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	cfg := base
+	RegisterFlags(fs, &cfg)
+	err := fs.Parse(argv)
+	return cfg, fs, err
+}
+
+func TestRegisterFlags(t *testing.T) {
+	base := Config{Level: InfoLevel, Encoding: "json", OutputPaths: []string{"stdout"}}
+	tests := []struct {
+		argv []string
+		want Config
+	}{
+		{nil, base},
+		{
+			[]string{"--log-level", "warning", "--log-format=Console"},
+			Config{Level: WarningLevel, Encoding: "console", OutputPaths: []string{"stdout"}},
+		},
+		{
+			[]string{"-log-output", "stderr", "-log-output", "/var/log/app.log"},
+			Config{Level: InfoLevel, Encoding: "json", OutputPaths: []string{"stderr", "/var/log/app.log"}},
+		},
+		{
+			[]string{"--log-dev"},
+			Config{Mode: ModeDevelopment, Level: InfoLevel, Encoding: "json", OutputPaths: []string{"stdout"}},
+		},
+		{
+			[]string{"--log-dev=false", "--log-level=DEBUG"},
+			Config{Mode: ModeProduction, Level: DebugLevel, Encoding: "json", OutputPaths: []string{"stdout"}},
+		},
+	}
+	for _, tt := range tests {
+		got, _, err := parseFlags(base, tt.argv...)
+		if err != nil {
+			t.Errorf("%q: got error %v", tt.argv, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: got config %+v, want %+v", tt.argv, got, tt.want)
+		}
+	}
+}
+
+func TestRegisterFlagsErrors(t *testing.T) {
+	tests := []struct {
+		argv []string
+		want string
+	}{
+		{[]string{"--log-level", "verbose"}, "verbose"},
+		{[]string{"--log-format", "logfmt"}, `unknown log format "logfmt"`},
+		{[]string{"--log-format", "xml"}, `unknown log format "xml"`},
+		{[]string{"--log-output", ""}, "empty path"},
+		{[]string{"--log-dev=maybe"}, "log-dev"},
+	}
+	for _, tt := range tests {
+		if _, _, err := parseFlags(Config{}, tt.argv...); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%q: got error %v, want it to contain %q", tt.argv, err, tt.want)
+		}
+	}
+}
+
+func TestNewFromFlags(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	_, fs, err := parseFlags(Config{DisableInitialFields: true}, "--log-level=error", "--log-output", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lg, err := NewFromFlags(fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lg.Enabled(WarningLevel) || !lg.Enabled(ErrorLevel) {
+		t.Errorf("the logger does not use the parsed level")
+	}
+
+	if _, err := NewFromFlags(flag.NewFlagSet("empty", flag.ContinueOnError)); err == nil {
+		t.Errorf("got no error without the registered flags")
+	}
+}
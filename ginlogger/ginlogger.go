@@ -0,0 +1,187 @@
+// Package ginlogger provides Gin middlewares logging the requests
+// with the github.com/Aibier/go-logger Logger.
+package ginlogger
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	logger "github.com/Aibier/go-logger"
+	"github.com/gin-gonic/gin"
+)
+
+// ContextKey is the gin context key of the request scoped logger.
+const ContextKey = "logger"
+
+// RequestIDHeader is the header holding the request id.
+const RequestIDHeader = "X-Request-ID"
+
+type config struct {
+	skipPaths map[string]bool
+	headers   []string
+	masker    *logger.Masker
+}
+
+// GinOption configures the GinMiddleware.
+type GinOption func(*config)
+
+// WithSkipPaths disables the access logs of the given route
+// patterns or paths, e.g. "/health".
+func WithSkipPaths(paths ...string) GinOption {
+	return func(c *config) {
+		for _, p := range paths {
+			c.skipPaths[p] = true
+		}
+	}
+}
+
+// WithHeaders adds the given request headers to the access logs,
+// masked using the header masker.
+func WithHeaders(names ...string) GinOption {
+	return func(c *config) {
+		c.headers = append(c.headers, names...)
+	}
+}
+
+// WithHeaderMasker sets the masker of the logged headers,
+// logger.DefaultMasker by default.
+func WithHeaderMasker(m *logger.Masker) GinOption {
+	return func(c *config) {
+		c.masker = m
+	}
+}
+
+// GinMiddleware returns a middleware storing a request scoped logger
+// in the gin context and in the request context, see FromGinContext and
+// logger.FromContextLogger, and logging an access entry per request.
+// The request id is read from the X-Request-ID header when the request
+// context doesn't have one.
+// The entries are logged as errors for 5xx status codes, as warnings for
+// 4xx ones and as info otherwise.
+func GinMiddleware(l logger.Logger, opts ...GinOption) gin.HandlerFunc {
+	cfg := config{
+		skipPaths: map[string]bool{},
+		masker:    logger.DefaultMasker,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return func(c *gin.Context) {
+		start := time.Now()
+		ctx := c.Request.Context()
+		if id := c.GetHeader(RequestIDHeader); id != "" && logger.FromContext(ctx) == "" {
+			ctx = logger.NewContext(ctx, id)
+		}
+		reqLogger := l.WithContext(ctx)
+		c.Request = c.Request.WithContext(logger.NewContextWithLogger(ctx, reqLogger))
+		c.Set(ContextKey, reqLogger)
+
+		completed := false
+		defer func() {
+			// a panic propagating to an outer GinRecovery
+			if !completed {
+				logAccess(c, reqLogger, cfg, start, http.StatusInternalServerError)
+			}
+		}()
+		c.Next()
+		completed = true
+		logAccess(c, reqLogger, cfg, start, c.Writer.Status())
+	}
+}
+
+// logAccess logs the access entry of a request.
+func logAccess(c *gin.Context, l logger.Logger, cfg config, start time.Time, status int) {
+	route := c.FullPath()
+	if cfg.skipPaths[route] || cfg.skipPaths[c.Request.URL.Path] {
+		return
+	}
+	if route == "" {
+		route = "unmatched"
+	}
+	size := c.Writer.Size()
+	if size < 0 {
+		size = 0
+	}
+	fields := []interface{}{
+		"method", c.Request.Method,
+		"route", route,
+		"status", status,
+		"latency", time.Since(start),
+		"bytes_out", size,
+		"client_ip", c.ClientIP(),
+	}
+	if len(cfg.headers) > 0 {
+		fields = append(fields, "headers", maskHeaders(c.Request.Header, cfg.headers, cfg.masker))
+	}
+	if len(c.Errors) > 0 {
+		fields = append(fields, "error", c.Errors.String())
+	}
+	l.With(fields...).Log(statusLevel(status), "request completed")
+}
+
+// GinRecovery returns a middleware recovering the panics of the handlers,
+// logging them with their stack trace, see logger.Logger.LogPanic, before
+// responding with a 500 status. The request scoped logger is used when
+// available. When GinRecovery is registered before GinMiddleware, the
+// GinMiddleware logs the 500 access entry of the panicking requests.
+func GinRecovery(l logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+			if r == http.ErrAbortHandler {
+				panic(r)
+			}
+			requestLogger(c, l).LogPanic(r, "route", c.FullPath())
+			c.AbortWithStatus(http.StatusInternalServerError)
+		}()
+		c.Next()
+	}
+}
+
+// requestLogger returns the request scoped logger, l if none.
+func requestLogger(c *gin.Context, l logger.Logger) logger.Logger {
+	if v, ok := c.Get(ContextKey); ok {
+		if lg, ok := v.(logger.Logger); ok {
+			return lg
+		}
+	}
+	return l
+}
+
+// FromGinContext returns the request scoped logger stored by the
+// GinMiddleware, the zero-value Logger if none.
+func FromGinContext(c *gin.Context) logger.Logger {
+	return requestLogger(c, logger.Logger{})
+}
+
+// statusLevel returns the level of the access entries.
+func statusLevel(status int) logger.Level {
+	switch {
+	case status >= http.StatusInternalServerError:
+		return logger.ErrorLevel
+	case status >= http.StatusBadRequest:
+		return logger.WarningLevel
+	default:
+		return logger.InfoLevel
+	}
+}
+
+// maskHeaders returns the values of the given headers masked as
+// header lines, so the masker header rules apply.
+func maskHeaders(h http.Header, names []string, m *logger.Masker) map[string]string {
+	out := make(map[string]string, len(names))
+	for _, name := range names {
+		v := h.Get(name)
+		if v == "" {
+			continue
+		}
+		prefix := http.CanonicalHeaderKey(name) + ": "
+		out[name] = strings.TrimPrefix(m.MaskString(prefix+v), prefix)
+	}
+	return out
+}
@@ -0,0 +1,136 @@
+package ginlogger
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	logger "github.com/Aibier/go-logger"
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func serve(r *gin.Engine, path string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// accessEntries returns the recorded access entries.
+func accessEntries(rec *logger.Recorder) []logger.LogEntry {
+	var out []logger.LogEntry
+	for _, e := range rec.Entries() {
+		if e.Message() == "request completed" {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func TestGinMiddleware(t *testing.T) {
+	rec := logger.NewRecorder()
+	lg := logger.NewWithWriter(logger.Config{}, rec)
+	r := gin.New()
+	r.Use(GinMiddleware(lg, WithSkipPaths("/health"), WithHeaders("Authorization")))
+	r.GET("/users/:id", func(c *gin.Context) {
+		FromGinContext(c).Info("handler")
+		logger.FromContextLogger(c.Request.Context()).Info("from context")
+		c.Status(http.StatusTeapot)
+	})
+	r.GET("/health", func(c *gin.Context) {})
+
+	serve(r, "/users/42", map[string]string{
+		"Authorization": "Bearer abcdefghijkl",
+		RequestIDHeader: "req-1",
+	})
+	serve(r, "/health", nil)
+	serve(r, "/missing", nil)
+
+	for _, e := range rec.Entries()[:2] {
+		if v, _ := e.Field("request_id"); v != "req-1" {
+			t.Errorf("%s: got request id %v", e.Message(), v)
+		}
+	}
+	access := accessEntries(rec)
+	if len(access) != 2 {
+		t.Fatalf("got %d access entries, want 2:\n%s", len(access), rec.Dump())
+	}
+	tests := []struct {
+		key  string
+		want interface{}
+	}{
+		{"route", "/users/:id"},
+		{"status", http.StatusTeapot},
+		{"method", http.MethodGet},
+	}
+	for _, tt := range tests {
+		if v, _ := access[0].Field(tt.key); v != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.key, v, tt.want)
+		}
+	}
+	if access[0].Level != logger.WarningLevel {
+		t.Errorf("got a %s entry for a 4xx status", access[0].Level)
+	}
+	if headers, _ := access[0].Field("headers"); strings.Contains(fmt.Sprint(headers), "abcdefghijkl") {
+		t.Errorf("the authorization header was not masked: %v", headers)
+	}
+	if v, _ := access[1].Field("route"); v != "unmatched" {
+		t.Errorf("got route %v for an unmatched path", v)
+	}
+}
+
+func TestGinRecovery(t *testing.T) {
+	for _, outermost := range []bool{false, true} {
+		rec := logger.NewRecorder()
+		lg := logger.NewWithWriter(logger.Config{}, rec)
+		r := gin.New()
+		if outermost {
+			r.Use(GinRecovery(lg), GinMiddleware(lg))
+		} else {
+			r.Use(GinMiddleware(lg), GinRecovery(lg))
+		}
+		r.GET("/panic/:id", func(c *gin.Context) { panic("boom") })
+
+		w := serve(r, "/panic/1", map[string]string{RequestIDHeader: "req-1"})
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("outermost %v: got status %d", outermost, w.Code)
+		}
+
+		var panics []logger.LogEntry
+		for _, e := range rec.Entries() {
+			if e.Message() == logger.PanicMessage {
+				panics = append(panics, e)
+			}
+		}
+		if len(panics) != 1 {
+			t.Fatalf("outermost %v: got %d panic entries:\n%s", outermost, len(panics), rec.Dump())
+		}
+		p := panics[0]
+		if v, _ := p.Field("panic"); v != "boom" || p.Level != logger.ErrorLevel {
+			t.Errorf("outermost %v: got panic entry %v", outermost, p)
+		}
+		if v, _ := p.Field("stack"); !strings.Contains(v.(string), "ginlogger_test.go") {
+			t.Errorf("outermost %v: the stack is missing the handler: %v", outermost, v)
+		}
+		if v, _ := p.Field("request_id"); v != "req-1" {
+			t.Errorf("outermost %v: got request id %v, want the request scoped logger", outermost, v)
+		}
+
+		access := accessEntries(rec)
+		if len(access) != 1 {
+			t.Fatalf("outermost %v: got %d access entries:\n%s", outermost, len(access), rec.Dump())
+		}
+		if v, _ := access[0].Field("status"); v != http.StatusInternalServerError || access[0].Level != logger.ErrorLevel {
+			t.Errorf("outermost %v: got access entry %v", outermost, access[0])
+		}
+	}
+}
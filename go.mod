@@ -2,12 +2,10 @@ module github.com/Aibier/go-logger
 
 go 1.19
 
-require go.uber.org/zap v1.15.0
-
-require (
-	github.com/stretchr/testify v1.6.1 // indirect
-	go.uber.org/atomic v1.6.0 // indirect
-	go.uber.org/multierr v1.5.0 // indirect
-	golang.org/x/lint v0.0.0-20210508222113-6edffad5e616 // indirect
-	golang.org/x/tools v0.5.0 // indirect
-)
+require go.uber.org/zap v1.27.0
+
+require go.uber.org/multierr v1.10.0
+
+require gopkg.in/yaml.v3 v3.0.1
+
+require github.com/google/go-cmp v0.6.0
@@ -0,0 +1,266 @@
+// Package hclogger provides a hashicorp go-hclog Logger
+// writing through the github.com/Aibier/go-logger Logger.
+package hclogger
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"strings"
+	"sync/atomic"
+
+	logger "github.com/Aibier/go-logger"
+	"github.com/hashicorp/go-hclog"
+)
+
+// HCLogAdapter is a hclog.Logger writing through a Logger.
+type HCLogAdapter struct {
+	// base is the Logger with the implied args,
+	// l the base one with the name field.
+	base    logger.Logger
+	l       logger.Logger
+	name    string
+	implied []interface{}
+	level   *atomic.Int32
+}
+
+var _ hclog.Logger = HCLogAdapter{}
+
+// NewHCLogAdapter returns a hclog.Logger writing to l, with a "logger"
+// field holding the name when not empty, and reporting the caller of
+// its methods:
+//
+//	raftConfig.Logger = hclogger.NewHCLogAdapter(lg, "raft")
+//
+// The hclog trace entries are logged as debug. The levels enabled
+// are the ones enabled by the Logger, SetLevel adds a minimum level
+// shared by the derived loggers.
+func NewHCLogAdapter(l logger.Logger, name string) hclog.Logger {
+	h := HCLogAdapter{base: l.WithCallerSkip(hclogCallerSkip), level: new(atomic.Int32)}
+	h.level.Store(int32(hclog.Trace))
+	return h.withName(name)
+}
+
+// hclogCallerSkip is the number of adapter frames of the entries,
+// the exported method and log.
+const hclogCallerSkip = 2
+
+// Log implements hclog.Logger, the entries without level are logged as info.
+func (h HCLogAdapter) Log(level hclog.Level, msg string, args ...interface{}) {
+	h.log(level, msg, args)
+}
+
+// Trace implements hclog.Logger.
+func (h HCLogAdapter) Trace(msg string, args ...interface{}) {
+	h.log(hclog.Trace, msg, args)
+}
+
+// Debug implements hclog.Logger.
+func (h HCLogAdapter) Debug(msg string, args ...interface{}) {
+	h.log(hclog.Debug, msg, args)
+}
+
+// Info implements hclog.Logger.
+func (h HCLogAdapter) Info(msg string, args ...interface{}) {
+	h.log(hclog.Info, msg, args)
+}
+
+// Warn implements hclog.Logger.
+func (h HCLogAdapter) Warn(msg string, args ...interface{}) {
+	h.log(hclog.Warn, msg, args)
+}
+
+// Error implements hclog.Logger.
+func (h HCLogAdapter) Error(msg string, args ...interface{}) {
+	h.log(hclog.Error, msg, args)
+}
+
+// log logs an entry, it must be called by the exported methods.
+func (h HCLogAdapter) log(level hclog.Level, msg string, args []interface{}) {
+	if !h.enabled(level) {
+		return
+	}
+	l := h.l
+	if len(args) > 0 {
+		l = l.With(args...)
+	}
+	l.Log(Level(level), msg)
+}
+
+// IsTrace implements hclog.Logger.
+func (h HCLogAdapter) IsTrace() bool {
+	return h.enabled(hclog.Trace)
+}
+
+// IsDebug implements hclog.Logger.
+func (h HCLogAdapter) IsDebug() bool {
+	return h.enabled(hclog.Debug)
+}
+
+// IsInfo implements hclog.Logger.
+func (h HCLogAdapter) IsInfo() bool {
+	return h.enabled(hclog.Info)
+}
+
+// IsWarn implements hclog.Logger.
+func (h HCLogAdapter) IsWarn() bool {
+	return h.enabled(hclog.Warn)
+}
+
+// IsError implements hclog.Logger.
+func (h HCLogAdapter) IsError() bool {
+	return h.enabled(hclog.Error)
+}
+
+// enabled returns if the entries of the given level are logged.
+func (h HCLogAdapter) enabled(level hclog.Level) bool {
+	min := hclog.Level(h.level.Load())
+	if level == hclog.NoLevel {
+		level = hclog.Info
+	}
+	return min != hclog.Off && level != hclog.Off && level >= min && h.l.Enabled(Level(level))
+}
+
+// ImpliedArgs implements hclog.Logger, returning
+// the key/value pairs added by With.
+func (h HCLogAdapter) ImpliedArgs() []interface{} {
+	return h.implied
+}
+
+// With implements hclog.Logger.
+func (h HCLogAdapter) With(args ...interface{}) hclog.Logger {
+	if len(args) == 0 {
+		return h
+	}
+	h.base = h.base.With(args...)
+	h.l = h.l.With(args...)
+	h.implied = append(h.implied[:len(h.implied):len(h.implied)], args...)
+	return h
+}
+
+// Name implements hclog.Logger.
+func (h HCLogAdapter) Name() string {
+	return h.name
+}
+
+// Named implements hclog.Logger, the names are joined with dots.
+func (h HCLogAdapter) Named(name string) hclog.Logger {
+	if h.name != "" && name != "" {
+		name = h.name + "." + name
+	}
+	return h.withName(name)
+}
+
+// ResetNamed implements hclog.Logger.
+func (h HCLogAdapter) ResetNamed(name string) hclog.Logger {
+	return h.withName(name)
+}
+
+// withName returns a copy of the adapter with the given name,
+// replacing the name field of the current one.
+func (h HCLogAdapter) withName(name string) HCLogAdapter {
+	h.name = name
+	h.l = h.base
+	if name != "" {
+		h.l = h.base.Named(name)
+	}
+	return h
+}
+
+// SetLevel implements hclog.Logger, the level is
+// shared with the loggers derived from h.
+func (h HCLogAdapter) SetLevel(level hclog.Level) {
+	if level == hclog.NoLevel {
+		level = hclog.Trace
+	}
+	h.level.Store(int32(level))
+}
+
+// GetLevel implements hclog.Logger.
+func (h HCLogAdapter) GetLevel() hclog.Level {
+	return hclog.Level(h.level.Load())
+}
+
+// stdLoggerCallerSkip is the number of log.Logger frames
+// calling the writer of StandardLogger, e.g. Printf and output.
+const stdLoggerCallerSkip = 2
+
+// StandardLogger implements hclog.Logger, reporting
+// the caller of the log.Logger methods.
+func (h HCLogAdapter) StandardLogger(opts *hclog.StandardLoggerOptions) *log.Logger {
+	h.l = h.l.WithCallerSkip(stdLoggerCallerSkip)
+	return log.New(h.StandardWriter(opts), "", 0)
+}
+
+// StandardWriter implements hclog.Logger. The written lines are logged
+// as info, or with the ForceLevel option level, or with the level of
+// their "[LEVEL]" prefix with the InferLevels option.
+func (h HCLogAdapter) StandardWriter(opts *hclog.StandardLoggerOptions) io.Writer {
+	if opts == nil {
+		opts = &hclog.StandardLoggerOptions{}
+	}
+	return stdWriter{h: h, opts: *opts}
+}
+
+// levelPrefixes maps the level prefixes of the standard logger lines.
+var levelPrefixes = []struct {
+	prefix string
+	level  hclog.Level
+}{
+	{"[TRACE]", hclog.Trace},
+	{"[DEBUG]", hclog.Debug},
+	{"[INFO]", hclog.Info},
+	{"[WARN]", hclog.Warn},
+	{"[ERROR]", hclog.Error},
+	{"[ERR]", hclog.Error},
+}
+
+type stdWriter struct {
+	h    HCLogAdapter
+	opts hclog.StandardLoggerOptions
+}
+
+func (w stdWriter) Write(p []byte) (int, error) {
+	for _, line := range bytes.Split(bytes.TrimRight(p, "\n"), []byte("\n")) {
+		level, msg := w.parse(string(line))
+		w.h.log(level, msg, nil)
+	}
+	return len(p), nil
+}
+
+// parse returns the level and message of a standard logger line.
+func (w stdWriter) parse(line string) (hclog.Level, string) {
+	if w.opts.ForceLevel != hclog.NoLevel {
+		return w.opts.ForceLevel, line
+	}
+	if !w.opts.InferLevels {
+		return hclog.Info, line
+	}
+	s := line
+	if w.opts.InferLevelsWithTimestamp {
+		if i := strings.IndexByte(s, '['); i > 0 {
+			s = s[i:]
+		}
+	}
+	for _, p := range levelPrefixes {
+		if strings.HasPrefix(s, p.prefix) {
+			return p.level, strings.TrimSpace(s[len(p.prefix):])
+		}
+	}
+	return hclog.Info, line
+}
+
+// Level returns the Logger level of a hclog level, the trace entries
+// are logged as debug and the entries without level as info.
+func Level(level hclog.Level) logger.Level {
+	switch level {
+	case hclog.Trace, hclog.Debug:
+		return logger.DebugLevel
+	case hclog.Warn:
+		return logger.WarningLevel
+	case hclog.Error:
+		return logger.ErrorLevel
+	default:
+		return logger.InfoLevel
+	}
+}
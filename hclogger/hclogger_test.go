@@ -0,0 +1,171 @@
+package hclogger
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	logger "github.com/Aibier/go-logger"
+	"github.com/hashicorp/go-hclog"
+)
+
+// newAdapter returns an adapter named name writing to rec.
+func newAdapter(rec *logger.Recorder, name string) hclog.Logger {
+	return NewHCLogAdapter(logger.NewWithWriter(logger.Config{Level: logger.DebugLevel}, rec), name)
+}
+
+// names returns the "logger" fields of the last entry.
+func names(t *testing.T, rec *logger.Recorder) []interface{} {
+	t.Helper()
+	e, ok := rec.LastEntry()
+	if !ok {
+		t.Fatal("no entry")
+	}
+	var out []interface{}
+	for i := 0; i+1 < len(e.Fields); i += 2 {
+		if e.Fields[i] == logger.NameFieldKey {
+			out = append(out, e.Fields[i+1])
+		}
+	}
+	return out
+}
+
+func TestHCLogAdapterConformance(t *testing.T) {
+	rec := logger.NewRecorder()
+	h := newAdapter(rec, "raft")
+
+	calls := []struct {
+		log   func(msg string, args ...interface{})
+		level logger.Level
+	}{
+		{h.Trace, logger.DebugLevel},
+		{h.Debug, logger.DebugLevel},
+		{h.Info, logger.InfoLevel},
+		{h.Warn, logger.WarningLevel},
+		{h.Error, logger.ErrorLevel},
+		{func(msg string, args ...interface{}) { h.Log(hclog.NoLevel, msg, args...) }, logger.InfoLevel},
+	}
+	for i, c := range calls {
+		c.log("entry", "i", i)
+		e, _ := rec.LastEntry()
+		if e.Level != c.level || e.Message() != "entry" {
+			t.Errorf("call %d: got entry %v, want level %v", i, e, c.level)
+		}
+		if v, _ := e.Field("i"); v != i {
+			t.Errorf("call %d: got i %v", i, v)
+		}
+	}
+
+	w := h.With("peer", "a", "term", 3)
+	if got := w.ImpliedArgs(); len(got) != 4 || got[0] != "peer" {
+		t.Errorf("got implied args %v", got)
+	}
+	if len(h.ImpliedArgs()) != 0 {
+		t.Errorf("With modified the parent implied args")
+	}
+
+	n := w.Named("snapshot")
+	if n.Name() != "raft.snapshot" {
+		t.Errorf("got name %q", n.Name())
+	}
+	n.Info("named")
+	if got := names(t, rec); len(got) != 1 || got[0] != "raft.snapshot" {
+		t.Errorf("got logger fields %v, want a single one", got)
+	}
+	if e, _ := rec.LastEntry(); e.FieldMap()["peer"] != "a" {
+		t.Errorf("Named dropped the implied args: %v", e)
+	}
+
+	n.Named("store").ResetNamed("fsm").Info("reset")
+	if got := names(t, rec); len(got) != 1 || got[0] != "fsm" {
+		t.Errorf("got logger fields %v after ResetNamed", got)
+	}
+	h.ResetNamed("").Info("unnamed")
+	if got := names(t, rec); len(got) != 0 {
+		t.Errorf("got logger fields %v, want none", got)
+	}
+
+	w.SetLevel(hclog.Warn)
+	if h.GetLevel() != hclog.Warn || h.IsInfo() || !h.IsWarn() || !h.IsError() {
+		t.Errorf("the level is not shared with the parent")
+	}
+	rec.Reset()
+	n.Info("hidden")
+	n.Warn("visible")
+	if entries := rec.Entries(); len(entries) != 1 || entries[0].Message() != "visible" {
+		t.Errorf("got entries %v", entries)
+	}
+	h.SetLevel(hclog.Off)
+	h.Error("off")
+	if len(rec.Entries()) != 1 {
+		t.Errorf("an entry was logged with the Off level")
+	}
+	h.SetLevel(hclog.NoLevel)
+	if !h.IsTrace() || h.GetLevel() != hclog.Trace {
+		t.Errorf("NoLevel did not reset the level")
+	}
+}
+
+func TestHCLogAdapterStandardLogger(t *testing.T) {
+	rec := logger.NewRecorder()
+	h := newAdapter(rec, "")
+	h.StandardLogger(&hclog.StandardLoggerOptions{InferLevels: true}).Print("[WARN] disk almost full")
+	h.StandardLogger(&hclog.StandardLoggerOptions{ForceLevel: hclog.Error}).Print("[INFO] forced")
+	h.StandardLogger(nil).Print("[ERROR] not inferred")
+	h.StandardLogger(&hclog.StandardLoggerOptions{InferLevels: true, InferLevelsWithTimestamp: true}).Print("2024/03/01 12:00:00 [DEBUG] with timestamp")
+
+	want := []struct {
+		level logger.Level
+		msg   string
+	}{
+		{logger.WarningLevel, "disk almost full"},
+		{logger.ErrorLevel, "[INFO] forced"},
+		{logger.InfoLevel, "[ERROR] not inferred"},
+		{logger.DebugLevel, "with timestamp"},
+	}
+	entries := rec.Entries()
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries:\n%s", len(entries), rec.Dump())
+	}
+	for i, w := range want {
+		if entries[i].Level != w.level || entries[i].Message() != w.msg {
+			t.Errorf("entry %d: got %v, want %v %q", i, entries[i], w.level, w.msg)
+		}
+	}
+}
+
+func TestHCLogAdapterCaller(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.log")
+	lg, err := logger.New(logger.Config{Level: logger.DebugLevel, OutputPaths: []string{path}, DisableInitialFields: true, DisableStacktrace: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewHCLogAdapter(lg, "raft")
+	h.Info("info")
+	h.Log(hclog.Warn, "log")
+	h.With("k", 1).Named("n").Error("error")
+	h.StandardLogger(nil).Printf("standard %d", 1)
+	lg.Sync()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var n int
+	for sc := bufio.NewScanner(f); sc.Scan(); n++ {
+		var e struct{ Msg, Caller string }
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(e.Caller, "/hclogger_test.go:") {
+			t.Errorf("%s: got caller %q, want the adapter caller", e.Msg, e.Caller)
+		}
+	}
+	if n != 4 {
+		t.Errorf("got %d entries, want 4", n)
+	}
+}
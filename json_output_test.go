@@ -0,0 +1,54 @@
+package logger_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	logger "github.com/Aibier/go-logger"
+)
+
+// newJSONLogger returns a logger writing json entries to a temporary
+// file, and a function returning the entries written.
+func newJSONLogger(t *testing.T) (logger.Logger, func() []map[string]interface{}) {
+	path := filepath.Join(t.TempDir(), "out.log")
+	lg, err := logger.New(logger.Config{OutputPaths: []string{path}, DisableInitialFields: true, DisableStacktrace: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return lg, func() []map[string]interface{} {
+		lg.Sync()
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		var entries []map[string]interface{}
+		sc := bufio.NewScanner(f)
+		for sc.Scan() {
+			var e map[string]interface{}
+			if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+				t.Fatalf("decoding %s: %v", sc.Bytes(), err)
+			}
+			entries = append(entries, e)
+		}
+		return entries
+	}
+}
+
+// checkCallers checks that there are n entries, all reporting
+// a caller in the given file.
+func checkCallers(t *testing.T, entries []map[string]interface{}, n int, file string) {
+	t.Helper()
+	if len(entries) != n {
+		t.Fatalf("got %d entries, want %d", len(entries), n)
+	}
+	for _, e := range entries {
+		if caller, _ := e["caller"].(string); !strings.Contains(caller, "/"+file+":") {
+			t.Errorf("%v: got caller %q, want the adapter caller in %s", e["msg"], caller, file)
+		}
+	}
+}
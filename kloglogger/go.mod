@@ -0,0 +1,17 @@
+module github.com/Aibier/go-logger/kloglogger
+
+go 1.21
+
+require (
+	github.com/Aibier/go-logger v0.0.0
+	github.com/go-logr/logr v1.4.4
+	k8s.io/klog/v2 v2.140.0
+)
+
+require (
+	go.uber.org/multierr v1.10.0 // indirect
+	go.uber.org/zap v1.27.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/Aibier/go-logger => ../
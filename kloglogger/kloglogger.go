@@ -0,0 +1,173 @@
+// Package kloglogger redirects the k8s.io/klog/v2 output
+// to the github.com/Aibier/go-logger Logger.
+package kloglogger
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+
+	logger "github.com/Aibier/go-logger"
+	"github.com/go-logr/logr"
+	"k8s.io/klog/v2"
+)
+
+// DebugVerbosity is the lowest klog verbosity logged as debug,
+// the entries with a lower verbosity are logged as info.
+const DebugVerbosity = 2
+
+// klogPackage is the function name prefix of the klog functions.
+const klogPackage = "k8s.io/klog/v2."
+
+var mu sync.Mutex
+
+// RedirectKlog sends the klog entries to l, with a "logger" field
+// holding "klog":
+//
+//	func main() {
+//		lg, _ := logger.New(conf)
+//		kloglogger.RedirectKlog(lg)
+//		...
+//	}
+//
+// The INFO, WARNING and ERROR severities are logged as info, warning
+// and error, FATAL as error as klog exits by itself, and the entries
+// of verbosity DebugVerbosity or more as debug. The klog -v and
+// -vmodule flags still select which verbose entries are logged.
+// It's meant to be called once at startup, calling it again replaces
+// the previous Logger. It may be called after klog.InitFlags, the
+// output flags are then ignored until RestoreKlog is called.
+func RedirectKlog(l logger.Logger) {
+	mu.Lock()
+	defer mu.Unlock()
+	klog.SetLogger(logr.New(NewLogSink(l.Named("klog"))))
+}
+
+// RestoreKlog restores the klog default output, e.g. at the end of a test.
+func RestoreKlog() {
+	mu.Lock()
+	defer mu.Unlock()
+	klog.ClearLogger()
+}
+
+// LogSink is a logr.LogSink writing through a Logger. The entries
+// of verbosity DebugVerbosity or more are logged as debug, the
+// others as info, and the errors as error.
+type LogSink struct {
+	l    logger.Logger
+	name string
+}
+
+var (
+	_ logr.LogSink          = &LogSink{}
+	_ logr.CallDepthLogSink = &LogSink{}
+)
+
+// NewLogSink returns a LogSink writing through l, reporting the
+// caller of the logr.Logger methods:
+//
+//	log := logr.New(kloglogger.NewLogSink(lg))
+func NewLogSink(l logger.Logger) *LogSink {
+	return &LogSink{l: l.WithCallerSkip(1)}
+}
+
+// Init implements logr.LogSink, skipping the logr frames.
+func (s *LogSink) Init(info logr.RuntimeInfo) {
+	s.l = s.l.WithCallerSkip(info.CallDepth)
+}
+
+// WithCallDepth implements logr.CallDepthLogSink.
+func (s *LogSink) WithCallDepth(depth int) logr.LogSink {
+	c := *s
+	c.l = c.l.WithCallerSkip(depth)
+	return &c
+}
+
+// Enabled implements logr.LogSink. The verbosity 0 entries are
+// enabled if the errors are, klog logging its warnings with it.
+func (s *LogSink) Enabled(level int) bool {
+	switch {
+	case level >= DebugVerbosity:
+		return s.l.Enabled(logger.DebugLevel)
+	case level == 0:
+		return s.l.Enabled(logger.ErrorLevel)
+	default:
+		return s.l.Enabled(logger.InfoLevel)
+	}
+}
+
+// Info implements logr.LogSink.
+func (s *LogSink) Info(level int, msg string, keysAndValues ...interface{}) {
+	lvl := logger.InfoLevel
+	switch {
+	case level >= DebugVerbosity:
+		lvl = logger.DebugLevel
+	case level == 0:
+		lvl = klogSeverity()
+	}
+	s.with(keysAndValues).Log(lvl, msg)
+}
+
+// Error implements logr.LogSink.
+func (s *LogSink) Error(err error, msg string, keysAndValues ...interface{}) {
+	s.with(keysAndValues).WithError(err).Error(msg)
+}
+
+// WithValues implements logr.LogSink.
+func (s *LogSink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	c := *s
+	c.l = c.l.With(keysAndValues...)
+	return &c
+}
+
+// WithName implements logr.LogSink, the names are
+// joined with dots in the "name" field.
+func (s *LogSink) WithName(name string) logr.LogSink {
+	c := *s
+	if c.name != "" {
+		name = c.name + "." + name
+	}
+	c.name = name
+	return &c
+}
+
+// with returns the Logger with the name and the entry fields. The
+// klog logger names, passed as a leading "logger" pair, are joined
+// to the name rather than repeating the "logger" field.
+func (s *LogSink) with(keysAndValues []interface{}) logger.Logger {
+	l, name := s.l, s.name
+	if len(keysAndValues) >= 2 && keysAndValues[0] == logger.NameFieldKey {
+		if n, ok := keysAndValues[1].(string); ok {
+			if name != "" {
+				n = name + "." + n
+			}
+			name, keysAndValues = n, keysAndValues[2:]
+		}
+	}
+	if name != "" {
+		l = l.With("name", name)
+	}
+	return l.With(keysAndValues...)
+}
+
+// klogSeverity returns the level of a klog entry logged as logr info,
+// from the klog function called: klog sends its warnings and fatal
+// entries to the logr info method.
+func klogSeverity() logger.Level {
+	pc := make([]uintptr, 16)
+	frames := runtime.CallersFrames(pc[:runtime.Callers(3, pc)])
+	for {
+		f, more := frames.Next()
+		if name := strings.TrimPrefix(f.Function, klogPackage); name != f.Function {
+			switch {
+			case strings.HasPrefix(name, "Warning"):
+				return logger.WarningLevel
+			case strings.HasPrefix(name, "Fatal"), strings.HasPrefix(name, "Exit"):
+				return logger.ErrorLevel
+			}
+		}
+		if !more {
+			return logger.InfoLevel
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package kloglogger
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	logger "github.com/Aibier/go-logger"
+	"github.com/go-logr/logr"
+	"k8s.io/klog/v2"
+)
+
+// newJSONLogger returns a logger writing json entries to a temporary
+// file, and a function returning the entries written.
+func newJSONLogger(t *testing.T) (logger.Logger, func() []map[string]interface{}) {
+	path := filepath.Join(t.TempDir(), "out.log")
+	lg, err := logger.New(logger.Config{Level: logger.DebugLevel, OutputPaths: []string{path}, DisableInitialFields: true, DisableStacktrace: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return lg, func() []map[string]interface{} {
+		lg.Sync()
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		var entries []map[string]interface{}
+		sc := bufio.NewScanner(f)
+		for sc.Scan() {
+			var e map[string]interface{}
+			if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+				t.Fatalf("decoding %s: %v", sc.Bytes(), err)
+			}
+			entries = append(entries, e)
+		}
+		return entries
+	}
+}
+
+func TestRedirectKlog(t *testing.T) {
+	fs := flag.NewFlagSet("klog", flag.ContinueOnError)
+	klog.InitFlags(fs)
+	if err := fs.Set("v", "2"); err != nil {
+		t.Fatal(err)
+	}
+	defer fs.Set("v", "0")
+
+	lg, entries := newJSONLogger(t)
+	RedirectKlog(lg)
+	defer RestoreKlog()
+	klog.Info("info")
+	klog.Warningf("warning %d", 1)
+	klog.ErrorS(os.ErrNotExist, "error", "pod", "web-0")
+	klog.V(2).InfoS("verbose")
+	klog.Background().WithName("cache").WithValues("k", 1).Info("named")
+
+	got := entries()
+	want := []struct{ level, msg string }{
+		{"info", "info"}, {"warn", "warning 1"}, {"error", "error"}, {"debug", "verbose"}, {"info", "named"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d entries, want %d: %v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		e := got[i]
+		if e["level"] != w.level || e["msg"] != w.msg || e[logger.NameFieldKey] != "klog" {
+			t.Errorf("entry %d: got %v, want %s %q", i, e, w.level, w.msg)
+		}
+		if caller, _ := e["caller"].(string); !strings.Contains(caller, "/kloglogger_test.go:") {
+			t.Errorf("entry %d: got caller %q, want the klog caller", i, caller)
+		}
+	}
+	if e := got[2]; e["pod"] != "web-0" || e["error"] != os.ErrNotExist.Error() {
+		t.Errorf("got error entry %v", e)
+	}
+	if e := got[4]; e["name"] != "cache" || e["k"] != 1.0 {
+		t.Errorf("got named entry %v", e)
+	}
+}
+
+func TestLogSinkCaller(t *testing.T) {
+	lg, entries := newJSONLogger(t)
+	log := logr.New(NewLogSink(lg))
+	log.Info("info")
+	log.V(DebugVerbosity).Info("debug")
+	log.WithValues("k", 1).Error(os.ErrClosed, "error")
+	func() {
+		helper, log := log.WithCallStackHelper()
+		helper()
+		log.Info("helper")
+	}()
+
+	got := entries()
+	if len(got) != 4 {
+		t.Fatalf("got %d entries: %v", len(got), got)
+	}
+	for _, e := range got {
+		if caller, _ := e["caller"].(string); !strings.Contains(caller, "/kloglogger_test.go:") {
+			t.Errorf("%v: got caller %q, want the logr caller", e["msg"], caller)
+		}
+	}
+}
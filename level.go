@@ -0,0 +1,141 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// MarshalText implements encoding.TextMarshaler, using String.
+// Unknown levels can't be marshalled.
+func (l Level) MarshalText() ([]byte, error) {
+	if !l.valid() {
+		return nil, fmt.Errorf("unknown log level %d", int(l))
+	}
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, see ParseLevel.
+func (l *Level) UnmarshalText(text []byte) error {
+	level, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler, the level
+// is marshalled as a string, see MarshalText.
+func (l Level) MarshalJSON() ([]byte, error) {
+	text, err := l.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(text))
+}
+
+// UnmarshalJSON implements json.Unmarshaler, the
+// level must be a string, see UnmarshalText.
+func (l *Level) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("log level must be a string: %w", err)
+	}
+	return l.UnmarshalText([]byte(s))
+}
+
+// Set implements flag.Value, see ParseLevel:
+//
+//	level := logger.InfoLevel
+//	flag.Var(&level, "log-level", "minimum log level")
+func (l *Level) Set(s string) error {
+	return l.UnmarshalText([]byte(s))
+}
+
+// AtomicLevel is a minimum enabled level that can be changed at
+// runtime, safe for concurrent use. The zero value is not usable,
+// use NewAtomicLevel:
+//
+//	level := logger.NewAtomicLevel(logger.InfoLevel)
+//	lg, err := logger.New(logger.Config{AtomicLevel: level})
+//	...
+//	level.SetLevel(logger.DebugLevel)
+type AtomicLevel struct {
+	v *levelVar
+}
+
+// NewAtomicLevel returns an AtomicLevel set to l.
+func NewAtomicLevel(l Level) AtomicLevel {
+	return AtomicLevel{v: newLevelVar(l)}
+}
+
+// Level returns the minimum enabled level.
+func (a AtomicLevel) Level() Level {
+	if a.v == nil {
+		return DebugLevel
+	}
+	return a.v.get()
+}
+
+// SetLevel changes the minimum enabled level.
+func (a AtomicLevel) SetLevel(l Level) {
+	if a.v != nil {
+		a.v.set(l)
+	}
+}
+
+// levelVar returns the level of the config, its AtomicLevel when set.
+func (c Config) levelVar() *levelVar {
+	if c.AtomicLevel.v != nil {
+		return c.AtomicLevel.v
+	}
+	return newLevelVar(c.Level)
+}
+
+// levelVar holds the minimum enabled level shared by a logger
+// and its zap writer, safe for concurrent use.
+type levelVar struct {
+	level atomic.Int32
+	zap   zap.AtomicLevel
+}
+
+func newLevelVar(l Level) *levelVar {
+	v := &levelVar{zap: zap.NewAtomicLevel()}
+	v.set(l)
+	return v
+}
+
+// get returns the minimum enabled level.
+func (v *levelVar) get() Level {
+	return Level(v.level.Load())
+}
+
+// set changes the minimum enabled level.
+func (v *levelVar) set(l Level) {
+	v.level.Store(int32(l))
+	v.zap.SetLevel(zapLevel(l))
+}
+
+// zapLevel returns the zap level matching l.
+func zapLevel(l Level) zapcore.Level {
+	switch {
+	case l <= DebugLevel:
+		return zapcore.DebugLevel
+	case l == InfoLevel:
+		return zapcore.InfoLevel
+	case l == WarningLevel:
+		return zapcore.WarnLevel
+	case l == ErrorLevel:
+		return zapcore.ErrorLevel
+	case l == DPanicLevel:
+		return zapcore.DPanicLevel
+	case l == PanicLevel:
+		return zapcore.PanicLevel
+	default:
+		return zapcore.FatalLevel
+	}
+}
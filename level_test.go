@@ -0,0 +1,161 @@
+package logger
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Level
+		wantErr string
+	}{
+		{"", DebugLevel, `unknown log level ""`},
+		{"   ", DebugLevel, `unknown log level "   "`},
+		{"\tinfo\n", InfoLevel, ""},
+		{" Warning ", WarningLevel, ""},
+		{"ERROR", ErrorLevel, ""},
+		{"DPanic", DPanicLevel, ""},
+		{"pAnIc", PanicLevel, ""},
+		{"Fatal", FatalLevel, ""},
+		{"trace", DebugLevel, `unknown log level "trace"`},
+		{"in fo", DebugLevel, `unknown log level "in fo"`},
+	}
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.in)
+		if got != tt.want {
+			t.Errorf("%q: got level %s, want %s", tt.in, got, tt.want)
+		}
+		if errStr(err) != tt.wantErr {
+			t.Errorf("%q: got error %v, want %q", tt.in, err, tt.wantErr)
+		}
+		if l := LevelFromString(tt.in); l != tt.want {
+			t.Errorf("%q: LevelFromString got %s, want %s", tt.in, l, tt.want)
+		}
+	}
+}
+
+// errStr returns the message of err, empty if nil.
+func errStr(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func TestLevelText(t *testing.T) {
+	levels := []Level{DebugLevel, InfoLevel, WarningLevel, ErrorLevel, DPanicLevel, PanicLevel, FatalLevel}
+	for _, l := range levels {
+		text, err := l.MarshalText()
+		if err != nil || string(text) != l.String() {
+			t.Errorf("%s: MarshalText got %q, %v", l, text, err)
+		}
+		var got Level
+		if err := got.UnmarshalText(text); err != nil || got != l {
+			t.Errorf("%s: UnmarshalText got %s, %v", l, got, err)
+		}
+
+		data, err := json.Marshal(struct{ Level Level }{l})
+		if want := `{"Level":"` + l.String() + `"}`; err != nil || string(data) != want {
+			t.Errorf("%s: json.Marshal got %s, %v, want %s", l, data, err, want)
+		}
+		var cfg struct{ Level Level }
+		if err := json.Unmarshal(data, &cfg); err != nil || cfg.Level != l {
+			t.Errorf("%s: json.Unmarshal got %s, %v", l, cfg.Level, err)
+		}
+
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		flagLevel := InfoLevel
+		fs.Var(&flagLevel, "log-level", "")
+		if err := fs.Parse([]string{"-log-level", l.String()}); err != nil || flagLevel != l {
+			t.Errorf("%s: flag got %s, %v", l, flagLevel, err)
+		}
+		if flagLevel.String() != l.String() {
+			t.Errorf("%s: flag String got %s", l, flagLevel.String())
+		}
+	}
+}
+
+func TestLevelTextErrors(t *testing.T) {
+	if _, err := Level(42).MarshalText(); err == nil {
+		t.Error("MarshalText: no error for level 42")
+	}
+	if _, err := json.Marshal(Level(42)); err == nil {
+		t.Error("json.Marshal: no error for level 42")
+	}
+
+	level := WarningLevel
+	if err := level.UnmarshalText([]byte("verbose")); err == nil || level != WarningLevel {
+		t.Errorf("UnmarshalText: got %s, %v, want unchanged level and an error", level, err)
+	}
+	for _, data := range []string{`"verbose"`, `2`, `null`, `{}`} {
+		level := WarningLevel
+		if err := json.Unmarshal([]byte(data), &level); err == nil || level != WarningLevel {
+			t.Errorf("json.Unmarshal %s: got %s, %v, want unchanged level and an error", data, level, err)
+		}
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	level = InfoLevel
+	fs.Var(&level, "log-level", "")
+	if err := fs.Parse([]string{"-log-level", "verbose"}); err == nil || level != InfoLevel {
+		t.Errorf("flag: got %s, %v, want unchanged level and an error", level, err)
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	levels := []Level{DebugLevel, InfoLevel, WarningLevel, ErrorLevel, DPanicLevel, PanicLevel, FatalLevel}
+	for _, min := range levels {
+		rec := NewRecorder()
+		lg := NewWithWriter(Config{Level: min}, rec)
+		for _, l := range levels {
+			if got, want := lg.Enabled(l), l >= min; got != want {
+				t.Errorf("logger level %s, entry level %s: got %v, want %v", min, l, got, want)
+			}
+		}
+	}
+
+	var zero Logger
+	for _, l := range levels {
+		if zero.Enabled(l) {
+			t.Errorf("zero value logger: %s enabled", l)
+		}
+	}
+}
+
+func TestParseLevelNumericAndAliases(t *testing.T) {
+	tests := map[string]Level{
+		"0": DebugLevel, "1": InfoLevel, "2": WarningLevel, "3": ErrorLevel,
+		"4": DPanicLevel, "5": PanicLevel, " 6 ": FatalLevel,
+		"warn": WarningLevel, "WARN": WarningLevel,
+		"err": ErrorLevel, " Err ": ErrorLevel,
+		"crit": FatalLevel, "CRIT": FatalLevel,
+		"critical": FatalLevel, "Critical\t": FatalLevel,
+	}
+	for in, want := range tests {
+		if got, err := ParseLevel(in); err != nil || got != want {
+			t.Errorf("%q: got %s, %v, want %s", in, got, err, want)
+		}
+	}
+
+	for _, in := range []string{"-1", "7", "42"} {
+		got, err := ParseLevel(in)
+		if want := `log level "` + in + `" out of range 0-6`; errStr(err) != want {
+			t.Errorf("%q: got error %v, want %q", in, err, want)
+		}
+		if !got.valid() {
+			t.Errorf("%q: got invalid level %d", in, int(got))
+		}
+	}
+
+	for _, l := range []Level{DebugLevel, InfoLevel, WarningLevel, ErrorLevel, DPanicLevel, PanicLevel, FatalLevel} {
+		if got, err := ParseLevel(l.String()); err != nil || got != l {
+			t.Errorf("%s: String round-trip got %s, %v", l, got, err)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package logger
+
+// LeveledLogger adapts a Logger to the leveled logger interfaces taking
+// a message and key/value pairs, like the hashicorp retryablehttp
+// LeveledLogger:
+//
+//	client := retryablehttp.NewClient()
+//	client.Logger = logger.NewLeveledLogger(lg.WithContext(ctx))
+type LeveledLogger struct {
+	l Logger
+}
+
+// NewLeveledLogger returns a LeveledLogger writing through l,
+// reporting the caller of its methods.
+func NewLeveledLogger(l Logger) LeveledLogger {
+	return LeveledLogger{l: l.WithCallerSkip(1)}
+}
+
+// Error logs an error message with the given fields.
+func (ll LeveledLogger) Error(msg string, keysAndValues ...interface{}) {
+	ll.l.Errorw(msg, keysAndValues...)
+}
+
+// Info logs an info message with the given fields.
+func (ll LeveledLogger) Info(msg string, keysAndValues ...interface{}) {
+	ll.l.Infow(msg, keysAndValues...)
+}
+
+// Debug logs a debug message with the given fields.
+func (ll LeveledLogger) Debug(msg string, keysAndValues ...interface{}) {
+	ll.l.Debugw(msg, keysAndValues...)
+}
+
+// Warn logs a warning message with the given fields.
+func (ll LeveledLogger) Warn(msg string, keysAndValues ...interface{}) {
+	ll.l.Warnw(msg, keysAndValues...)
+}
@@ -0,0 +1,89 @@
+package logger_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	logger "github.com/Aibier/go-logger"
+)
+
+// leveledLogger is the retryablehttp LeveledLogger interface.
+type leveledLogger interface {
+	Error(msg string, keysAndValues ...interface{})
+	Info(msg string, keysAndValues ...interface{})
+	Debug(msg string, keysAndValues ...interface{})
+	Warn(msg string, keysAndValues ...interface{})
+}
+
+// retryGet gets url until it succeeds, logging as retryablehttp does.
+func retryGet(l leveledLogger, url string, attempts int) error {
+	for i := 0; i < attempts; i++ {
+		l.Debug("performing request", "method", http.MethodGet, "url", url)
+		resp, err := http.Get(url)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode < 500 {
+				return nil
+			}
+			err = errors.New(resp.Status)
+		}
+		if remain := attempts - i - 1; remain > 0 {
+			l.Debug("retrying request", "request", http.MethodGet+" "+url, "timeout", time.Millisecond, "remaining", remain)
+			continue
+		}
+		l.Error("request failed", "error", err, "method", http.MethodGet, "url", url)
+		return err
+	}
+	return nil
+}
+
+func TestLeveledLogger(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls++; calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	rec := logger.NewRecorder()
+	lg := logger.NewWithWriter(logger.Config{}, rec).With("request_id", "req-1")
+	if err := retryGet(logger.NewLeveledLogger(lg), srv.URL, 3); err != nil {
+		t.Fatal(err)
+	}
+	entries := rec.Entries()
+	if len(entries) != 5 {
+		t.Fatalf("got %d entries, want 3 attempts and 2 retries:\n%s", len(entries), rec.Dump())
+	}
+	retry := entries[1]
+	if retry.Message() != "retrying request" || retry.Level != logger.DebugLevel {
+		t.Errorf("got retry entry %v", retry)
+	}
+	for k, want := range map[string]interface{}{"remaining": 2, "request_id": "req-1"} {
+		if v, _ := retry.Field(k); v != want {
+			t.Errorf("%s: got %v, want %v", k, v, want)
+		}
+	}
+
+	rec.Reset()
+	calls = -10
+	if err := retryGet(logger.NewLeveledLogger(lg), srv.URL, 2); err == nil {
+		t.Fatal("got no error")
+	}
+	last, _ := rec.LastEntry()
+	if v, _ := last.Field("error"); last.Level != logger.ErrorLevel || !strings.Contains(v.(string), "503") {
+		t.Errorf("got failure entry %v", last)
+	}
+}
+
+func TestLeveledLoggerCaller(t *testing.T) {
+	lg, entries := newJSONLogger(t)
+	ll := logger.NewLeveledLogger(lg)
+	ll.Info("info", "k", 1)
+	ll.Warn("warn")
+	checkCallers(t, entries(), 2, "leveled_test.go")
+}
@@ -0,0 +1,61 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// lineWriter logs every line written to it.
+type lineWriter struct {
+	mu    sync.Mutex
+	l     Logger
+	level Level
+	buf   bytes.Buffer
+}
+
+// Writer returns a writer logging every line written to it as an entry
+// with the given level, e.g. to capture the output of a subprocess:
+//
+//	cmd.Stderr = logger.NewMaskingWriter(lg.Writer(logger.WarningLevel), nil)
+//
+// Data is buffered until a new line, or maxMaskingBuffer bytes, Close
+// logs the pending data. Empty lines are not logged.
+func (l Logger) Writer(level Level) io.WriteCloser {
+	return &lineWriter{l: l, level: level}
+}
+
+func (lw *lineWriter) Write(p []byte) (int, error) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+
+	lw.buf.Write(p)
+	for {
+		data := lw.buf.Bytes()
+		end := bytes.IndexByte(data, '\n') + 1
+		if end == 0 && len(data) < maxMaskingBuffer {
+			return len(p), nil
+		}
+		if end == 0 {
+			end = len(data)
+		}
+		lw.log(lw.buf.Next(end))
+	}
+}
+
+// Close logs the buffered data.
+func (lw *lineWriter) Close() error {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	lw.log(lw.buf.Next(lw.buf.Len()))
+	return nil
+}
+
+// log logs a line without its trailing new line.
+func (lw *lineWriter) log(line []byte) {
+	line = bytes.TrimRight(line, "\r\n")
+	if len(line) == 0 {
+		return
+	}
+	lw.l.Log(lw.level, string(line))
+}
@@ -2,15 +2,60 @@ package logger
 
 import (
 	"context"
+	"fmt"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // Config for logger
 type Config struct {
-	// Log is a key property that will change the logging mode.
-	// Use "Dev" to enable development mode.
+	// Mode is the logging mode, ModeProduction by default.
+	// The goVersion, pid and hostname fields are added to
+	// the entries in both modes.
+	Mode Mode
+
+	// Log is the legacy logging mode, used when Mode is empty:
+	// "dev" or "development" for ModeDevelopment, "prod" or
+	// "production" for ModeProduction, case insensitive. Other
+	// non-empty values are invalid.
+	//
+	// Deprecated: use Mode.
 	Log string
 
+	// Encoding is the format of the entries, "json" or "console".
+	// By default ModeDevelopment uses the console one and the
+	// production mode the json one.
+	Encoding string
+
+	// TimeFormat is the format of the entries timestamp: "iso8601",
+	// the default, "rfc3339", "rfc3339nano", "epoch" for the seconds
+	// since the Unix epoch, "epochmillis" for the integer milliseconds,
+	// "epochnanos", or else a time.Format layout.
+	TimeFormat string
+
+	// TimeKey is the key of the entries timestamp, "ts" by default.
+	TimeKey string
+
+	// DurationFormat is the format of the time.Duration fields:
+	// "millis" for the float milliseconds, the default, "seconds"
+	// for the float seconds, "nanos" for the integer nanoseconds,
+	// or "string" for the time.Duration String, e.g. "1.5s".
+	DurationFormat string
+
+	// FieldKeys overrides the keys of the entries fields.
+	FieldKeys FieldKeys
+
+	// InitialFields are added to every entry, e.g. the service name
+	// and version. They take precedence over the goVersion, pid and
+	// hostname fields added by the default writer.
+	InitialFields map[string]interface{}
+
+	// DisableInitialFields when true the default writer doesn't
+	// add the goVersion, pid and hostname fields to the entries,
+	// and doesn't look up the hostname.
+	DisableInitialFields bool
+
 	// Level is the minimum enabled logging level.
 	// Messages with a lower level will be discarded.
 	// DebugLevel will be used by default.
@@ -18,10 +63,24 @@ type Config struct {
 	// the level string representation.
 	Level Level
 
+	// AtomicLevel when set replaces Level, allowing to change the
+	// level of the loggers using it at runtime, see NewAtomicLevel.
+	AtomicLevel AtomicLevel
+
 	// OutputPaths can be used to defined the logger
-	// output channels. "stdout" by default.
+	// output channels. "stdout" by default, also when empty.
+	// Empty paths are invalid.
 	OutputPaths []string
 
+	// ErrorOutputPaths the outputs of the writer internal errors,
+	// e.g. failed writes. "stderr" by default, also when empty.
+	ErrorOutputPaths []string
+
+	// Outputs when not empty replaces OutputPaths, each output
+	// having its own minimum level on top of the logger one, e.g.
+	// every entry to stdout and the errors to a file too.
+	Outputs []Output
+
 	// CtxMiddlewares an arbitrary number of
 	// custom context middleware to run when
 	// logging an entry with context.
@@ -31,10 +90,105 @@ type Config struct {
 	// adding the default ctx middlewares.
 	SkipDefaultMiddlewares bool
 
+	// DisableCaller when true the entries are not
+	// annotated with the file and line of their caller.
+	DisableCaller bool
+
+	// CallerSkip the number of additional stack frames to skip to
+	// report the caller of the entries, e.g. 1 when the logger is
+	// wrapped by a helper. See Logger.WithCallerSkip.
+	CallerSkip int
+
+	// Sampling when set limits the number of identical
+	// entries written by the default writer.
+	Sampling *SamplingConfig
+
 	// DisableStacktrace when true the stack
 	// trace won't be added to log entries
 	// above info level
 	DisableStacktrace bool
+
+	// StacktraceLevel when set the stack trace is added to the
+	// entries at or above this level, instead of the error ones,
+	// or the warning ones in ModeDevelopment. DisableStacktrace takes
+	// precedence.
+	StacktraceLevel *Level
+
+	// OnSyncError is called with the errors returned when syncing the
+	// outputs, except the ones of outputs that can't be synced, like
+	// terminals and pipes. They are printed to stderr by default.
+	OnSyncError func(error)
+
+	// OnValidateWarning is called by Validate with the settings that
+	// are accepted but likely a mistake, e.g. an empty, rather than
+	// nil, OutputPaths falling back to the default output.
+	OnValidateWarning func(warning string)
+
+	// Clock is used to timestamp the log entries.
+	// The system clock will be used by default.
+	Clock Clock
+
+	// RedactKeys the field keys whose values will be
+	// replaced by "[REDACTED]", case insensitive.
+	// Wildcards are supported, e.g. "*_token".
+	RedactKeys []string
+
+	// MaskOutput when true every encoded entry is masked
+	// using the Masker before being written.
+	MaskOutput bool
+
+	// MaskFieldValues when true the string and []byte field values
+	// are masked using the Masker before reaching the writer.
+	// When MaskOutput is enabled too the default writer only masks
+	// the encoded entries, so values are never masked twice.
+	MaskFieldValues bool
+
+	// Masker masquerades the secrets from the log output
+	// when MaskOutput or MaskFieldValues are enabled. DefaultMasker by default.
+	Masker *Masker
+
+	// StrictFields when true an error entry naming the caller
+	// is logged for every malformed field list, with a dangling
+	// key or non-string keys, besides repairing it.
+	StrictFields bool
+
+	// AllowedFieldKeys when not empty only the fields with
+	// these keys are kept, case insensitive, wildcards are supported.
+	// The request_id field and the keys of the entry itself
+	// (ts, level, msg, caller, stacktrace) are always allowed.
+	AllowedFieldKeys []string
+
+	// CountDroppedFields when true the fields dropped by
+	// AllowedFieldKeys are replaced by a single "dropped_fields"
+	// field counting the ones of every entry.
+	CountDroppedFields bool
+
+	// HashKeys the field keys whose values will be replaced by
+	// their hash, see MaskModeHash. Case insensitive, wildcards
+	// are supported. RedactKeys take precedence.
+	HashKeys map[string]bool
+
+	// HashSecret the secret used to hash the values of the HashKeys
+	// and of the MaskModeHash rules. When empty the HashSecretEnv
+	// environment variable is used.
+	HashSecret Secret
+}
+
+// FieldKeys are the keys of the entries fields, the empty ones
+// keep their default. A "-" key drops the field, e.g. the caller.
+type FieldKeys struct {
+	// MessageKey "msg" by default.
+	MessageKey string
+	// LevelKey "level" by default.
+	LevelKey string
+	// TimeKey "ts" by default, it takes precedence over Config.TimeKey.
+	TimeKey string
+	// CallerKey "caller" by default.
+	CallerKey string
+	// StacktraceKey "stacktrace" by default.
+	StacktraceKey string
+	// NameKey "logger" by default.
+	NameKey string
 }
 
 // CtxMiddleware is a middleware that will be executed every time
@@ -51,39 +205,83 @@ const (
 	InfoLevel
 	WarningLevel
 	ErrorLevel
+	// DPanicLevel entries panic in ModeDevelopment, after being
+	// written, and are logged as errors in production mode.
+	DPanicLevel
 	PanicLevel
 	FatalLevel
 )
 
-var levelNames = []string{"debug", "info", "warning", "error", "panic", "fatal"}
+var levelNames = []string{"debug", "info", "warning", "error", "dpanic", "panic", "fatal"}
 
 // String return the string representation of a log level.
+// Unknown levels are represented as "level(N)".
 func (l Level) String() string {
+	if !l.valid() {
+		return "level(" + strconv.Itoa(int(l)) + ")"
+	}
 	return levelNames[l]
 }
 
-// LevelFromString returns the logger level according to the
-// given string representation, the level match will be evaluated
-// as case insensitive.
-//
-// If the level is unknown it will return DebugLevel.
-func LevelFromString(level string) Level {
-	switch strings.ToLower(level) {
+// valid returns if l is a known level.
+func (l Level) valid() bool {
+	return l >= DebugLevel && int(l) < len(levelNames)
+}
+
+// orInfo returns l, InfoLevel if it is unknown, as the
+// entries with an unknown level are logged as info.
+func (l Level) orInfo() Level {
+	if !l.valid() {
+		return InfoLevel
+	}
+	return l
+}
+
+// ParseLevel returns the logger level according to the given string
+// representation, case insensitive and ignoring the surrounding spaces.
+// The "warn", "err", "crit" and "critical" aliases are accepted too, the
+// latter ones for FatalLevel, and the numeric values of the Level
+// constants, e.g. "3" for ErrorLevel. An error is returned if the
+// level is unknown, or if the number is out of range.
+func ParseLevel(level string) (Level, error) {
+	switch s := strings.ToLower(strings.TrimSpace(level)); s {
 	case "debug":
-		return DebugLevel
+		return DebugLevel, nil
 	case "info":
-		return InfoLevel
-	case "warning":
-		return WarningLevel
-	case "error":
-		return ErrorLevel
+		return InfoLevel, nil
+	case "warning", "warn":
+		return WarningLevel, nil
+	case "error", "err":
+		return ErrorLevel, nil
+	case "dpanic":
+		return DPanicLevel, nil
 	case "panic":
-		return PanicLevel
-	case "fatal":
-		return FatalLevel
+		return PanicLevel, nil
+	case "fatal", "crit", "critical":
+		return FatalLevel, nil
 	default:
+		if n, err := strconv.Atoi(s); err == nil {
+			if l := Level(n); l.valid() {
+				return l, nil
+			}
+			return DebugLevel, fmt.Errorf("log level %q out of range %d-%d", level, DebugLevel, FatalLevel)
+		}
+		return DebugLevel, fmt.Errorf("unknown log level %q", level)
+	}
+}
+
+// LevelFromString returns the logger level according to the
+// given string representation, the level match will be evaluated
+// as case insensitive. The aliases and numeric values accepted by
+// ParseLevel are accepted too.
+//
+// If the level is unknown it will return DebugLevel, see ParseLevel.
+func LevelFromString(level string) Level {
+	l, err := ParseLevel(level)
+	if err != nil {
 		return DebugLevel
 	}
+	return l
 }
 
 // DefaultMiddlewares the default middlewares that will be used on new loggers.
@@ -92,26 +290,61 @@ var DefaultMiddlewares = []CtxMiddleware{RequestIDMiddleware}
 // Logger can write log entries using different writer.
 type Logger struct {
 	writer         Writer
-	level          Level
+	level          *levelVar
 	ctxMiddlewares []CtxMiddleware
+	fields         *fieldProcessor
+	debug          *debugState
+	name           string
+	dropped        int
+	timeFormat     string
+	durationFormat string
 }
 
 // New creates a new logger with the default writer.
+// An error is returned if the config is invalid, see Config.Validate.
 func New(cfg Config) (Logger, error) {
-	w, err := newZapLogger(cfg, 2)
+	if err := cfg.Validate(); err != nil {
+		return Logger{}, fmt.Errorf("invalid logger config: %w", err)
+	}
+	level := cfg.levelVar()
+	w, err := newZapLogger(cfg, 2+cfg.CallerSkip, level)
 	if err != nil {
 		return Logger{}, err
 	}
 
-	return NewWithWriter(cfg, w), nil
+	l := NewWithWriter(cfg, w)
+	l.level = level
+	if len(l.debug.outputs) == 0 {
+		l.debug.outputs = []string{"stdout"}
+		if cfg.development() {
+			l.debug.outputs = []string{"stderr"}
+		}
+	}
+	return l, nil
 }
 
 // NewWithWriter creates a new logger with a specific writer.
 func NewWithWriter(cfg Config, writer Writer) Logger {
+	if _, ok := writer.(zapLogger); ok && cfg.MaskOutput {
+		cfg.MaskFieldValues = false
+	}
 	l := Logger{
 		writer:         writer,
-		ctxMiddlewares: cfg.CtxMiddlewares,
-		level:          cfg.Level,
+		ctxMiddlewares: append([]CtxMiddleware(nil), cfg.CtxMiddlewares...),
+		level:          cfg.levelVar(),
+		fields:         newFieldProcessor(cfg),
+		debug:          newDebugState(cfg),
+		timeFormat:     cfg.TimeFormat,
+		durationFormat: cfg.DurationFormat,
+	}
+	if c, ok := writer.(interface{ SetClock(Clock) }); ok && cfg.Clock != nil {
+		c.SetClock(cfg.Clock)
+	}
+	if _, ok := writer.(zapLogger); !ok && len(cfg.InitialFields) > 0 {
+		l = l.With(mapFields(cfg.InitialFields)...)
+	}
+	if _, ok := writer.(zapLogger); !ok {
+		l = l.WithCallerSkip(cfg.CallerSkip)
 	}
 	if cfg.SkipDefaultMiddlewares {
 		return l
@@ -132,80 +365,253 @@ func Must(l Logger, err error) Logger {
 
 // Debug logs a debug message.
 func (l Logger) Debug(args ...interface{}) {
-	l.Log(DebugLevel, args...)
+	l.log(DebugLevel, args...)
 }
 
 // Debugf logs a debug message indicating a printf compatible format.
 func (l Logger) Debugf(str string, args ...interface{}) {
-	l.Logf(DebugLevel, str, args...)
+	l.logf(DebugLevel, str, args...)
 }
 
 // Info logs an info message.
 func (l Logger) Info(args ...interface{}) {
-	l.Log(InfoLevel, args...)
+	l.log(InfoLevel, args...)
 }
 
 // Infof logs an info message indicating a printf compatible format.
 func (l Logger) Infof(str string, args ...interface{}) {
-	l.Logf(InfoLevel, str, args...)
+	l.logf(InfoLevel, str, args...)
 }
 
 // Warn logs a warning message.
 func (l Logger) Warn(args ...interface{}) {
-	l.Log(WarningLevel, args...)
+	l.log(WarningLevel, args...)
 }
 
 // Warnf logs a warning message indicating a printf compatible format.
 func (l Logger) Warnf(str string, args ...interface{}) {
-	l.Logf(WarningLevel, str, args...)
+	l.logf(WarningLevel, str, args...)
 }
 
 // Info logs an error message.
 func (l Logger) Error(args ...interface{}) {
-	l.Log(ErrorLevel, args...)
+	l.log(ErrorLevel, args...)
 }
 
 // Errorf logs an error message indicating a printf compatible format.
 func (l Logger) Errorf(str string, args ...interface{}) {
-	l.Logf(ErrorLevel, str, args...)
+	l.logf(ErrorLevel, str, args...)
+}
+
+// DPanic logs a dpanic message, panicking after writing
+// it in ModeDevelopment, see DPanicLevel.
+func (l Logger) DPanic(args ...interface{}) {
+	l.log(DPanicLevel, args...)
+}
+
+// DPanicf logs a dpanic message indicating a printf compatible
+// format, panicking after writing it in ModeDevelopment.
+func (l Logger) DPanicf(str string, args ...interface{}) {
+	l.logf(DPanicLevel, str, args...)
 }
 
 // Panic logs an panic Level message and triggers a panic.
 func (l Logger) Panic(args ...interface{}) {
-	l.Log(PanicLevel, args...)
+	l.log(PanicLevel, args...)
 }
 
 // Panicf logs a panic message indicating a printf compatible format
 // and triggers a panic.
 func (l Logger) Panicf(str string, args ...interface{}) {
-	l.Logf(PanicLevel, str, args...)
+	l.logf(PanicLevel, str, args...)
 }
 
 // Fatal logs an fatal Level message and terminate the execution.
 func (l Logger) Fatal(args ...interface{}) {
-	l.Log(FatalLevel, args...)
+	l.log(FatalLevel, args...)
 }
 
 // Fatalf logs a fatal message indicating a printf compatible format
 // and terminate the execution.
 func (l Logger) Fatalf(str string, args ...interface{}) {
-	l.Logf(FatalLevel, str, args...)
+	l.logf(FatalLevel, str, args...)
+}
+
+// Debugw logs a debug message with key/value pairs, see Logw.
+func (l Logger) Debugw(msg string, keysAndValues ...interface{}) {
+	l.logw(DebugLevel, msg, keysAndValues)
+}
+
+// Infow logs an info message with key/value pairs, see Logw.
+func (l Logger) Infow(msg string, keysAndValues ...interface{}) {
+	l.logw(InfoLevel, msg, keysAndValues)
+}
+
+// Warnw logs a warning message with key/value pairs, see Logw.
+func (l Logger) Warnw(msg string, keysAndValues ...interface{}) {
+	l.logw(WarningLevel, msg, keysAndValues)
+}
+
+// Errorw logs an error message with key/value pairs, see Logw.
+func (l Logger) Errorw(msg string, keysAndValues ...interface{}) {
+	l.logw(ErrorLevel, msg, keysAndValues)
+}
+
+// DPanicw logs a dpanic message with key/value pairs, panicking
+// after writing it in ModeDevelopment, see Logw.
+func (l Logger) DPanicw(msg string, keysAndValues ...interface{}) {
+	l.logw(DPanicLevel, msg, keysAndValues)
+}
+
+// Panicw logs a panic message with key/value pairs
+// and triggers a panic, see Logw.
+func (l Logger) Panicw(msg string, keysAndValues ...interface{}) {
+	l.logw(PanicLevel, msg, keysAndValues)
+}
+
+// Fatalw logs a fatal message with key/value pairs
+// and terminate the execution, see Logw.
+func (l Logger) Fatalw(msg string, keysAndValues ...interface{}) {
+	l.logw(FatalLevel, msg, keysAndValues)
+}
+
+// Logw logs a message with key/value pairs added to the entry only,
+// without deriving a logger as With does:
+//
+//	lg.Infow("request served", "status", 200, "path", r.URL.Path)
+//
+// The pairs are repaired, rendered and redacted as the With fields
+// are, a dangling key getting the "(MISSING)" value. Writers
+// implementing WriterW log them directly.
+func (l Logger) Logw(level Level, msg string, keysAndValues ...interface{}) {
+	l.logw(level, msg, keysAndValues)
 }
 
 // Log logs a message
 func (l Logger) Log(level Level, args ...interface{}) {
-	if level < l.level {
+	l.log(level, args...)
+}
+
+// Logf logs a message indicating a printf compatible format.
+// Without args str is logged verbatim.
+func (l Logger) Logf(level Level, str string, args ...interface{}) {
+	l.logf(level, str, args...)
+}
+
+// log writes an entry. Every exported logging method must call it
+// directly so the writers see the same call depth, and report the
+// right caller.
+func (l Logger) log(level Level, args ...interface{}) {
+	if !l.enabled(level) {
 		return
 	}
-	l.innerWriter().Log(level, args...)
+	msg := message{args: args}
+	l.debug.record(level, l.name, msg)
+	l.flushBeforeFatal(level)
+	defer l.flushOnPanic(level, msg)
+	if l.writer == nil {
+		warnMissingWriter()
+	}
+	l.entryWriter().Log(level, args...)
 }
 
-// Logf logs a message indicating a printf compatible format
-func (l Logger) Logf(level Level, str string, args ...interface{}) {
-	if level < l.level {
+// logf writes a printf compatible entry, see log.
+func (l Logger) logf(level Level, str string, args ...interface{}) {
+	if !l.enabled(level) {
 		return
 	}
-	l.innerWriter().Logf(level, str, args...)
+	msg := message{str: str, args: args, format: true}
+	l.debug.record(level, l.name, msg)
+	l.flushBeforeFatal(level)
+	defer l.flushOnPanic(level, msg)
+	if l.writer == nil {
+		warnMissingWriter()
+	}
+	l.entryWriter().Logf(level, str, args...)
+}
+
+// logw writes an entry with key/value pairs, see log.
+func (l Logger) logw(level Level, msg string, keysAndValues []interface{}) {
+	if !l.enabled(level) {
+		return
+	}
+	l.debug.record(level, l.name, message{str: msg})
+	l.flushBeforeFatal(level)
+	defer l.flushOnPanic(level, message{str: msg})
+	if l.writer == nil {
+		warnMissingWriter()
+	}
+	keysAndValues, dropped := l.prepareFields(keysAndValues)
+	if dropped += l.dropped; dropped > 0 {
+		keysAndValues = append(keysAndValues, DroppedFieldsKey, dropped)
+	}
+	if w, ok := l.innerWriter().(WriterW); ok {
+		w.Logw(level, msg, keysAndValues...)
+		return
+	}
+	l.innerWriter().With(keysAndValues...).Log(level, msg)
+}
+
+// flushBeforeFatal syncs the writer before a fatal entry terminates
+// the execution, the zap writer syncs itself before exiting.
+func (l Logger) flushBeforeFatal(level Level) {
+	if level != FatalLevel {
+		return
+	}
+	if _, ok := l.writer.(zapLogger); !ok {
+		l.innerWriter().Sync()
+	}
+}
+
+// flushOnPanic syncs the writer after a panic entry is written, then
+// panics with the entry message, whether the writer panics or not.
+// When the writer panics itself, at any level, it is synced too and
+// its panic value propagates unchanged, e.g. a RecordedPanic.
+func (l Logger) flushOnPanic(level Level, msg message) {
+	r := recover()
+	if r == nil && level != PanicLevel {
+		return
+	}
+	l.innerWriter().Sync()
+	if r == nil {
+		r = msg.String()
+	}
+	panic(r)
+}
+
+// message is the message of an entry, built only when needed.
+type message struct {
+	str    string
+	args   []interface{}
+	format bool
+}
+
+func (m message) String() string {
+	switch {
+	case m.format:
+		return formatMessage(m.str, m.args)
+	case len(m.args) > 0:
+		return fmt.Sprint(m.args...)
+	}
+	return m.str
+}
+
+// Enabled returns if an entry with the given level would be written,
+// to skip building expensive entries:
+//
+//	if lg.Enabled(logger.DebugLevel) {
+//		lg.Debug("payload", dump(payload))
+//	}
+//
+// It is always false for the zero-value Logger.
+func (l Logger) Enabled(level Level) bool {
+	return l.writer != nil && l.enabled(level)
+}
+
+// enabled returns if entries with the given level are logged.
+// Unknown levels are logged as info.
+func (l Logger) enabled(level Level) bool {
+	return l.level == nil || level.orInfo() >= l.level.get()
 }
 
 // Cond logs a message with a different log level depending on the given condition
@@ -213,33 +619,80 @@ func (l Logger) Logf(level Level, str string, args ...interface{}) {
 // res, err := operationX()
 // logger.Cond(err == nil, logger.DebugLevel, logger.ErrorLevel, "operation X completed", "result", res)
 func (l Logger) Cond(condition bool, trueLvl, falseLvl Level, args ...interface{}) {
-	l.Log(conditional(condition, trueLvl, falseLvl), args...)
+	l.log(conditional(condition, trueLvl, falseLvl), args...)
 }
 
 // Condf logs a message with a conditional log level indicating a printf compatible format
 // See `Cond` for usage
 func (l Logger) Condf(condition bool, trueLvl, falseLvl Level, str string, args ...interface{}) {
-	l.Logf(conditional(condition, trueLvl, falseLvl), str, args...)
+	l.logf(conditional(condition, trueLvl, falseLvl), str, args...)
 }
 
 // With returns a new logger with fields that will be add to every log entry.
+// Dangling keys get the "(MISSING)" value and non-string keys are
+// stringified, see Config.StrictFields.
+// Errors, fmt.Stringers, times, durations and []byte values are rendered
+// to the same value by every writer, see Config.DurationFormat.
 func (l Logger) With(fields ...interface{}) Logger {
-	return l.clone(l.innerWriter().With(fields...))
+	fields, dropped := l.prepareFields(fields)
+	cp := l.clone(l.innerWriter().With(fields...))
+	cp.dropped += dropped
+	return cp
+}
+
+// NameFieldKey is the key of the field holding the logger name, see Named.
+const NameFieldKey = "logger"
+
+// Named returns a new logger adding a "logger" field with the given
+// name to every log entry, e.g. the name of the library an adapter
+// writes for. The name is reported by DebugState too.
+func (l Logger) Named(name string) Logger {
+	named := l.With(NameFieldKey, name)
+	named.name = name
+	return named
+}
+
+// WithFields returns a new logger with the fields of the map added to
+// every log entry, sorted by key, see With. The entries with an
+// empty key are skipped.
+func (l Logger) WithFields(fields map[string]interface{}) Logger {
+	if len(fields) == 0 {
+		return l
+	}
+	return l.With(mapFields(fields)...)
+}
+
+// prepareFields returns the fields repaired, rendered and
+// processed, see With, and the number of dropped fields to count.
+func (l Logger) prepareFields(fields []interface{}) ([]interface{}, int) {
+	fields, problem := repairFields(fields)
+	fields = normalizeFields(fields, l.timeFormat, l.durationFormat)
+	if problem != "" && l.fields != nil && l.fields.strict && l.enabled(ErrorLevel) {
+		l.innerWriter().Logf(ErrorLevel, "malformed log fields at %s: %s", externalCaller(), problem)
+	}
+	return l.fields.process(fields)
 }
 
 // WithMiddleware returns a new logger with more middlewares
 func (l Logger) WithMiddleware(middlewares ...CtxMiddleware) Logger {
 	cp := l.clone(l.innerWriter())
-	cp.ctxMiddlewares = append(cp.ctxMiddlewares, middlewares...)
+	// the middlewares are shared with the parent logger, force
+	// a new array so siblings don't overwrite each other.
+	n := len(cp.ctxMiddlewares)
+	cp.ctxMiddlewares = append(cp.ctxMiddlewares[:n:n], middlewares...)
 	return cp
 }
 
 // WithContext returns a new logger adding the fields that may be extracted
-// from the given context.
+// from the given context. A nil context is treated as context.Background,
+// and the fields of the middlewares that panic are skipped.
 func (l Logger) WithContext(ctx context.Context) Logger {
+	if ctx == nil {
+		ctx = context.Background()
+	}
 	var fields []interface{}
 	for _, m := range l.ctxMiddlewares {
-		if fs := m(ctx); len(fs) > 0 {
+		if fs := runMiddleware(ctx, m); len(fs) > 0 {
 			fields = append(fields, fs...)
 		}
 	}
@@ -249,11 +702,68 @@ func (l Logger) WithContext(ctx context.Context) Logger {
 	return l.With(fields...)
 }
 
+// runMiddleware returns the fields of the middleware,
+// none if it panics.
+func runMiddleware(ctx context.Context, m CtxMiddleware) (fields []interface{}) {
+	defer func() {
+		if recover() != nil {
+			fields = nil
+		}
+	}()
+	return m(ctx)
+}
+
 // WithError adds an error as a log field.
+// The logger is returned unchanged when err is nil, an interface
+// holding a nil pointer is not nil and is still added.
 func (l Logger) WithError(err error) Logger {
+	if err == nil {
+		return l
+	}
 	return l.With("error", err)
 }
 
+// GoroutineLabelKey is the field key used by WithGoroutineLabel.
+const GoroutineLabelKey = "goroutine"
+
+// WithCallerSkip returns a new logger skipping n additional stack
+// frames to report the caller of the entries, e.g. in a helper
+// wrapping the logger. The zap writer and the Recorder, see
+// WithCaller, honour it, the other writers are not affected.
+func (l Logger) WithCallerSkip(n int) Logger {
+	w, ok := l.writer.(interface{ WithCallerSkip(int) Writer })
+	if !ok || n == 0 {
+		return l
+	}
+	return l.clone(w.WithCallerSkip(n))
+}
+
+// WithTime returns a new logger timestamping its entries with t
+// rather than the time they are logged, e.g. to forward the entries
+// of another library keeping their time. The writers not supporting
+// it, see the zap writer and the Recorder, are not affected.
+func (l Logger) WithTime(t time.Time) Logger {
+	w, ok := l.writer.(interface{ WithTime(time.Time) Writer })
+	if !ok || t.IsZero() {
+		return l
+	}
+	return l.clone(w.WithTime(t))
+}
+
+// WithGoroutineLabel adds a label identifying the goroutine or worker
+// producing the log entries as a log field.
+func (l Logger) WithGoroutineLabel(label string) Logger {
+	return l.With(GoroutineLabelKey, label)
+}
+
+// SetLevel changes the minimum enabled level of the logger, of the
+// loggers derived from it, and of the loggers sharing its AtomicLevel.
+func (l Logger) SetLevel(level Level) {
+	if l.level != nil {
+		l.level.set(level)
+	}
+}
+
 // Sync ensures that all log entries are written.
 func (l Logger) Sync() {
 	l.innerWriter().Sync()
@@ -270,11 +780,26 @@ func (l Logger) innerWriter() Writer {
 	return l.writer
 }
 
+// entryWriter returns the writer of the entries without key/value
+// pairs, adding the count of the fields dropped by With if any.
+func (l Logger) entryWriter() Writer {
+	if l.dropped > 0 {
+		return l.innerWriter().With(DroppedFieldsKey, l.dropped)
+	}
+	return l.innerWriter()
+}
+
 func (l *Logger) clone(w Writer) Logger {
 	return Logger{
 		writer:         w,
 		ctxMiddlewares: l.ctxMiddlewares,
 		level:          l.level,
+		fields:         l.fields,
+		debug:          l.debug,
+		name:           l.name,
+		dropped:        l.dropped,
+		timeFormat:     l.timeFormat,
+		durationFormat: l.durationFormat,
 	}
 }
 
@@ -286,6 +811,23 @@ type Writer interface {
 	Sync()
 }
 
+// WriterW is implemented by the writers logging an entry with
+// key/value pairs without deriving a writer, see Logger.Logw.
+// The pairs are repaired, with string keys and an even length.
+type WriterW interface {
+	Logw(level Level, msg string, keysAndValues ...interface{})
+}
+
+// writew writes an entry with key/value pairs to w, through
+// a derived writer if w doesn't implement WriterW.
+func writew(w Writer, level Level, msg string, keysAndValues []interface{}) {
+	if ww, ok := w.(WriterW); ok {
+		ww.Logw(level, msg, keysAndValues...)
+		return
+	}
+	w.With(keysAndValues...).Log(level, msg)
+}
+
 func conditional(condition bool, trueLvl, falseLvl Level) Level {
 	if !condition {
 		return falseLvl
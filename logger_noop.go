@@ -1,5 +1,13 @@
 package logger
 
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
 type noOpLogger struct{}
 
 // NewNoOpLogger creates a logger with the no-op writer
@@ -17,8 +25,55 @@ func (z noOpLogger) Log(_ Level, _ ...interface{}) {}
 
 func (z noOpLogger) Logf(_ Level, _ string, _ ...interface{}) {}
 
+func (z noOpLogger) Logw(_ Level, _ string, _ ...interface{}) {}
+
 func (z noOpLogger) With(_ ...interface{}) Writer {
 	return z
 }
 
 func (z noOpLogger) Sync() {}
+
+// MissingWriterEnv the environment variable enabling the
+// DebugMissingWriter mode when set to "true", read once.
+const MissingWriterEnv = "LOGGER_DEBUG_MISSING_WRITER"
+
+// maxMissingWriterSites limits the number of call sites warned
+// about by the DebugMissingWriter mode.
+const maxMissingWriterSites = 1000
+
+var (
+	missingWriterOnce    sync.Once
+	missingWriterEnabled atomic.Bool
+	missingWriterSites   sync.Map // map[uintptr]struct{}
+	missingWriterCount   atomic.Int32
+	missingWriterOutput  io.Writer = os.Stderr
+)
+
+// DebugMissingWriter enables or disables the warnings written to stderr
+// when entries are logged through a zero-value Logger, which discards
+// them. A single warning is written for every call site.
+// It is disabled by default, unless the MissingWriterEnv environment
+// variable is set to "true".
+func DebugMissingWriter(enable bool) {
+	missingWriterOnce.Do(func() {})
+	missingWriterEnabled.Store(enable)
+}
+
+// warnMissingWriter warns about an entry logged through
+// a zero-value Logger, once per call site.
+func warnMissingWriter() {
+	missingWriterOnce.Do(func() {
+		missingWriterEnabled.Store(os.Getenv(MissingWriterEnv) == "true")
+	})
+	if !missingWriterEnabled.Load() {
+		return
+	}
+	f := externalFrame()
+	if _, seen := missingWriterSites.LoadOrStore(f.PC, struct{}{}); seen {
+		return
+	}
+	if missingWriterCount.Add(1) > maxMissingWriterSites {
+		return
+	}
+	fmt.Fprintf(missingWriterOutput, "logger: entry logged at %s:%d through a zero-value Logger was discarded\n", f.File, f.Line)
+}
@@ -0,0 +1,36 @@
+package logger_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	logger "github.com/Aibier/go-logger"
+)
+
+func TestDebugMissingWriter(t *testing.T) {
+	var out bytes.Buffer
+	defer logger.SetMissingWriterOutput(&out)()
+	logger.DebugMissingWriter(true)
+	defer logger.DebugMissingWriter(false)
+
+	var lg logger.Logger
+	for i := 0; i < 100; i++ {
+		lg.Infof("entry %d", i)
+	}
+	warnings := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "logger_noop_test.go:") {
+		t.Fatalf("got warnings %q, want one naming the call site", warnings)
+	}
+
+	lg.Error("another site")
+	if n := strings.Count(out.String(), "\n"); n != 2 {
+		t.Errorf("got %d warnings, want one per call site", n)
+	}
+
+	logger.DebugMissingWriter(false)
+	lg.Warn("disabled")
+	if n := strings.Count(out.String(), "\n"); n != 2 {
+		t.Errorf("got a warning with the mode disabled")
+	}
+}
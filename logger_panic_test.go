@@ -0,0 +1,167 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// asyncWriter writes the entries from a goroutine, Sync waiting
+// for the pending ones.
+type asyncWriter struct {
+	w       Writer
+	queue   chan func()
+	pending *sync.WaitGroup
+}
+
+func newAsyncWriter(w Writer) asyncWriter {
+	a := asyncWriter{w: w, queue: make(chan func(), 16), pending: &sync.WaitGroup{}}
+	go func() {
+		for fn := range a.queue {
+			fn()
+			a.pending.Done()
+		}
+	}()
+	return a
+}
+
+func (a asyncWriter) enqueue(fn func()) {
+	a.pending.Add(1)
+	a.queue <- fn
+}
+
+func (a asyncWriter) With(fields ...interface{}) Writer {
+	a.w = a.w.With(fields...)
+	return a
+}
+
+func (a asyncWriter) Log(level Level, args ...interface{}) {
+	a.enqueue(func() { a.w.Log(level, args...) })
+}
+
+func (a asyncWriter) Logf(level Level, str string, args ...interface{}) {
+	a.enqueue(func() { a.w.Logf(level, str, args...) })
+}
+
+func (a asyncWriter) Sync() {
+	a.pending.Wait()
+	a.w.Sync()
+}
+
+// recoverPanic returns the panic value of fn.
+func recoverPanic(fn func()) (v interface{}) {
+	defer func() { v = recover() }()
+	fn()
+	return nil
+}
+
+func TestPanicFlush(t *testing.T) {
+	calls := []struct {
+		name string
+		call func(Logger)
+	}{
+		{"Panic", func(l Logger) { l.Panic("boom ", 1) }},
+		{"Panicf", func(l Logger) { l.Panicf("boom %d", 1) }},
+		{"Panicw", func(l Logger) { l.Panicw("boom 1", "k", 1) }},
+		{"Log", func(l Logger) { l.Log(PanicLevel, "boom ", 1) }},
+	}
+	for _, c := range calls {
+		rec := NewRecorder()
+		async := newAsyncWriter(rec)
+		v := recoverPanic(func() { c.call(NewWithWriter(Config{}, async)) })
+		if v != "boom 1" {
+			t.Errorf("%s: got panic value %#v, want the message", c.name, v)
+		}
+		if e, ok := rec.LastEntry(); !ok || e.Level != PanicLevel || e.Message() != "boom 1" {
+			t.Errorf("%s: the entry was not written before the panic propagated: %v", c.name, rec.Entries())
+		}
+
+		rec = NewRecorder()
+		if v := recoverPanic(func() { c.call(NewWithWriter(Config{}, rec)) }); v != "boom 1" {
+			t.Errorf("%s: got panic value %#v from a recorder, want the message", c.name, v)
+		}
+
+		rec = NewRecorder(WithStrictSemantics())
+		v = recoverPanic(func() { c.call(NewWithWriter(Config{}, rec)) })
+		if p, ok := v.(RecordedPanic); !ok || p.Entry.Message() != "boom 1" {
+			t.Errorf("%s: got panic value %#v from a strict recorder, want a RecordedPanic", c.name, v)
+		}
+
+		path, sink := newMemorySink(t)
+		lg, err := New(Config{OutputPaths: []string{path}, DisableInitialFields: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v := recoverPanic(func() { c.call(lg) }); v != "boom 1" {
+			t.Errorf("%s: got panic value %#v from zap, want the message", c.name, v)
+		}
+		if out := sink.String(); !strings.Contains(out, `"level":"panic"`) || !strings.Contains(out, `"msg":"boom 1"`) {
+			t.Errorf("%s: zap output %s", c.name, out)
+		}
+	}
+}
+
+// panickingWriter panics with its error on every entry.
+type panickingWriter struct {
+	*Recorder
+	err error
+}
+
+func (p panickingWriter) Log(level Level, args ...interface{}) {
+	p.Recorder.Log(level, args...)
+	panic(p.err)
+}
+
+func TestWriterPanicFlush(t *testing.T) {
+	rec := NewRecorder()
+	w := panickingWriter{Recorder: rec, err: errors.New("writer failed")}
+	v := recoverPanic(func() { NewWithWriter(Config{}, w).Error("entry") })
+	if v != w.err {
+		t.Errorf("got panic value %#v, want the writer one", v)
+	}
+	if rec.SyncCount() != 1 {
+		t.Errorf("the writer was not synced before its panic propagated")
+	}
+
+	if v := recoverPanic(func() { NewWithWriter(Config{}, NewRecorder()).Error("entry") }); v != nil {
+		t.Errorf("an error entry panicked with %#v", v)
+	}
+}
+
+func TestFatalFlushBeforeExit(t *testing.T) {
+	path, sink := newMemorySink(t)
+	exited := make(chan string, 1)
+	defer func(fn func(int)) { exitFunc = fn }(exitFunc)
+	exitFunc = func(code int) {
+		exited <- fmt.Sprintf("exit %d\n%s", code, sink.String())
+	}
+
+	cfg := Config{OutputPaths: []string{path}, DisableInitialFields: true}
+	zw, err := newZapLogger(cfg, 2, cfg.levelVar())
+	if err != nil {
+		t.Fatal(err)
+	}
+	lg := NewWithWriter(cfg, newAsyncWriter(zw))
+	for i := 0; i < 50; i++ {
+		lg.Infof("entry %d", i)
+	}
+	lg.Fatal("last words")
+
+	var out string
+	select {
+	case out = <-exited:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the exit func was not called")
+	}
+	if !strings.HasPrefix(out, "exit 1\n") || !strings.Contains(out, `"msg":"last words"`) {
+		t.Errorf("got output at exit:\n%s", out)
+	}
+	for i := 0; i < 50; i++ {
+		if !strings.Contains(out, fmt.Sprintf(`"msg":"entry %d"`, i)) {
+			t.Errorf("entry %d was not written before exiting", i)
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func fieldMiddleware(key string) CtxMiddleware {
+	return func(context.Context) []interface{} { return []interface{}{key, true} }
+}
+
+func TestWithMiddlewareSiblings(t *testing.T) {
+	rec := NewRecorder()
+	parent := NewWithWriter(Config{SkipDefaultMiddlewares: true}, rec)
+	// spare capacity, so an append without copying would be shared
+	parent.ctxMiddlewares = append(make([]CtxMiddleware, 0, 8), fieldMiddleware("parent"))
+
+	a := parent.WithMiddleware(fieldMiddleware("a"))
+	b := parent.WithMiddleware(fieldMiddleware("b"))
+	a.WithContext(context.Background()).Info("a")
+	b.WithContext(context.Background()).Info("b")
+	parent.WithContext(context.Background()).Info("parent")
+
+	tests := []struct {
+		entry      int
+		has, hasnt []string
+	}{
+		{0, []string{"parent", "a"}, []string{"b"}},
+		{1, []string{"parent", "b"}, []string{"a"}},
+		{2, []string{"parent"}, []string{"a", "b"}},
+	}
+	entries := rec.Entries()
+	for _, tt := range tests {
+		e := entries[tt.entry]
+		for _, k := range tt.has {
+			if _, ok := e.Field(k); !ok {
+				t.Errorf("%s: missing the %s field: %v", e.Message(), k, e.Fields)
+			}
+		}
+		for _, k := range tt.hasnt {
+			if _, ok := e.Field(k); ok {
+				t.Errorf("%s: has the %s field of a sibling: %v", e.Message(), k, e.Fields)
+			}
+		}
+	}
+}
+
+func TestWithSiblings(t *testing.T) {
+	rec := NewRecorder()
+	parent := NewWithWriter(Config{}, rec).With("parent", 1)
+	a := parent.With("a", 1)
+	b := parent.With("b", 1)
+	a.Info("a")
+	b.Info("b")
+
+	entries := rec.Entries()
+	if _, ok := entries[0].Field("b"); ok {
+		t.Errorf("a has the field of b: %v", entries[0].Fields)
+	}
+	if _, ok := entries[1].Field("a"); ok {
+		t.Errorf("b has the field of a: %v", entries[1].Fields)
+	}
+}
+
+func TestNewWithWriterConfigMiddlewares(t *testing.T) {
+	middlewares := append(make([]CtxMiddleware, 0, 8), fieldMiddleware("tenant"))
+	cfg := Config{CtxMiddlewares: middlewares}
+	ctx := NewContext(context.Background(), "req-1")
+	for i := 0; i < 2; i++ {
+		rec := NewRecorder()
+		NewWithWriter(cfg, rec).WithContext(ctx).Info("request")
+
+		e, _ := rec.LastEntry()
+		var ids int
+		for j := 0; j+1 < len(e.Fields); j += 2 {
+			if e.Fields[j] == "request_id" {
+				ids++
+			}
+		}
+		if _, ok := e.Field("tenant"); !ok || ids != 1 {
+			t.Errorf("logger %d: got fields %v, want tenant and one request_id", i, e.Fields)
+		}
+	}
+	if len(cfg.CtxMiddlewares) != 1 || middlewares[:2][1] != nil {
+		t.Errorf("the config middlewares were modified")
+	}
+}
+
+func TestInvalidLevels(t *testing.T) {
+	levels := []Level{-100, -1, 7, 17, 1000}
+	for _, l := range levels {
+		if got, want := l.String(), fmt.Sprintf("level(%d)", int(l)); got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+
+	rec := NewRecorder()
+	lg := NewWithWriter(Config{}, rec)
+	var buf bytes.Buffer
+	zlg := NewWithWriter(Config{}, zapLogger{logger: zap.New(zapcore.NewCore(
+		zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "msg", LevelKey: "level", EncodeLevel: zapcore.LowercaseLevelEncoder}),
+		zapcore.AddSync(&buf), zapcore.DebugLevel)).Sugar()})
+	for _, l := range levels {
+		for _, w := range []Logger{lg, zlg} {
+			w.Log(l, "log")
+			w.Logf(l, "logf %d", 1)
+			w.Logw(l, "logw", "k", 1)
+		}
+	}
+
+	if n := len(rec.Entries()); n != 3*len(levels) {
+		t.Errorf("the recorder got %d entries, want %d", n, 3*len(levels))
+	}
+	if dump := string(rec.Dump()); strings.Count(dump, "[level(") != 3*len(levels) {
+		t.Errorf("the dump is missing entries:\n%s", dump)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3*len(levels) {
+		t.Fatalf("zap got %d entries, want %d:\n%s", len(lines), 3*len(levels), buf.String())
+	}
+	for i, line := range lines {
+		want := fmt.Sprintf(`"invalid_level":%d`, int(levels[i/3]))
+		if !strings.Contains(line, `"level":"info"`) || !strings.Contains(line, want) {
+			t.Errorf("got %s, want an info entry with %s", line, want)
+		}
+	}
+
+	rec.Reset()
+	NewWithWriter(Config{Level: WarningLevel}, rec).Log(17, "filtered as info")
+	if n := len(rec.Entries()); n != 0 {
+		t.Errorf("an unknown level passed the warning level")
+	}
+}
+
+func TestWithTime(t *testing.T) {
+	ts := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	rec := NewRecorder()
+	rec.SetClock(NewManualClock(ts.Add(time.Hour)))
+	lg := NewWithWriter(Config{}, rec)
+	lg.WithTime(ts).With("k", 1).Info("forwarded")
+	lg.Info("now")
+	entries := rec.Entries()
+	if len(entries) != 2 || !entries[0].Time.Equal(ts) || !entries[1].Time.Equal(ts.Add(time.Hour)) {
+		t.Errorf("got entries %v", entries)
+	}
+
+	path, sink := newMemorySink(t)
+	zl, err := New(Config{OutputPaths: []string{path}, DisableInitialFields: true, TimeFormat: "rfc3339"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	zl.WithTime(ts).Info("forwarded")
+	zl.WithTime(time.Time{}).Info("now")
+	lines := strings.Split(strings.TrimSpace(sink.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"ts":"2024-03-01T12:30:00Z"`) || strings.Contains(lines[1], "2024-03-01") {
+		t.Errorf("got output:\n%s", sink.String())
+	}
+}
+
+// sprintfWriter is a writer without Logw, always formatting
+// the Logf entries with fmt.Sprintf as many writers do.
+type sprintfWriter struct {
+	msgs *[]string
+}
+
+func (w sprintfWriter) With(...interface{}) Writer { return w }
+func (w sprintfWriter) Log(_ Level, args ...interface{}) {
+	*w.msgs = append(*w.msgs, fmt.Sprint(args...))
+}
+func (w sprintfWriter) Logf(_ Level, str string, args ...interface{}) {
+	*w.msgs = append(*w.msgs, fmt.Sprintf(str, args...))
+}
+func (w sprintfWriter) Sync() {}
+
+func TestLogwWithoutWriterW(t *testing.T) {
+	var msgs []string
+	lg := NewWithWriter(Config{}, sprintfWriter{&msgs})
+	lg.Infow("100% done", "k", 1)
+	lg.Logw(ErrorLevel, "disk at 99%d")
+	_, spy := NewSpyWriter(sprintfWriter{&msgs})
+	NewWithWriter(Config{}, spy).Infow("50% done", "k", 1)
+	want := []string{"100% done", "disk at 99%d", "50% done"}
+	if fmt.Sprint(msgs) != fmt.Sprint(want) {
+		t.Errorf("got messages %q, want them verbatim %q", msgs, want)
+	}
+}
+
+func TestWithFields(t *testing.T) {
+	rec := NewRecorder()
+	lg := NewWithWriter(Config{}, rec).With("base", true)
+
+	lg.WithFields(map[string]interface{}{
+		"zeta":  1,
+		"alpha": "a",
+		"":      "skipped",
+		"mid":   nil,
+	}).Info("fields")
+	lg.WithFields(nil).Info("nil map")
+	lg.WithFields(map[string]interface{}{}).Info("empty map")
+	lg.WithFields(map[string]interface{}{"": 1}).Info("empty keys only")
+
+	entries := rec.Entries()
+	if len(entries) != 4 {
+		t.Fatalf("got %d entries:\n%s", len(entries), rec.Dump())
+	}
+	want := []interface{}{"base", true, "alpha", "a", "mid", nil, "zeta", 1}
+	if fmt.Sprint(entries[0].Fields) != fmt.Sprint(want) {
+		t.Errorf("got fields %v, want %v", entries[0].Fields, want)
+	}
+	for _, e := range entries[1:] {
+		if fmt.Sprint(e.Fields) != fmt.Sprint([]interface{}{"base", true}) {
+			t.Errorf("%s: got fields %v, want the base ones", e.Message(), e.Fields)
+		}
+	}
+
+	for i := 0; i < 10; i++ {
+		rec.Reset()
+		lg.WithFields(map[string]interface{}{"c": 3, "b": 2, "a": 1, "d": 4}).Info("sorted")
+		if e, _ := rec.LastEntry(); fmt.Sprint(e.Fields) != "[base true a 1 b 2 c 3 d 4]" {
+			t.Fatalf("got fields %v, want them sorted by key", e.Fields)
+		}
+	}
+}
+
+// nilPtrError is an error type whose nil pointer is still
+// a non-nil error once stored in an interface.
+type nilPtrError struct{}
+
+func (*nilPtrError) Error() string { return "nil pointer error" }
+
+func TestWithError(t *testing.T) {
+	var typedNil *nilPtrError
+	tests := []struct {
+		name  string
+		err   error
+		added bool
+		want  interface{}
+	}{
+		{"nil", nil, false, nil},
+		{"typed nil", typedNil, true, "<nil>"},
+		{"non-nil", errors.New("boom"), true, "boom"},
+	}
+	for _, tt := range tests {
+		rec := NewRecorder()
+		lg := NewWithWriter(Config{}, rec).With("k", 1)
+		lg.WithError(tt.err).Info("done")
+
+		e, _ := rec.LastEntry()
+		v, ok := e.Field("error")
+		if ok != tt.added || v != tt.want {
+			t.Errorf("%s: got error field %v, %v", tt.name, v, ok)
+		}
+		if fmt.Sprint(e.Fields[:2]) != "[k 1]" {
+			t.Errorf("%s: got fields %v, want the logger ones kept", tt.name, e.Fields)
+		}
+	}
+}
+
+func TestWithNilContext(t *testing.T) {
+	rec := NewRecorder()
+	lg := NewWithWriter(Config{}, rec)
+	var nilCtx context.Context
+	lg.WithContext(nilCtx).Info("default middlewares")
+
+	deref := func(ctx context.Context) []interface{} {
+		return []interface{}{"deadline_set", ctx.Done() != nil, "user", ctx.Value(struct{}{})}
+	}
+	lg.WithMiddleware(deref).WithContext(nilCtx).Info("custom middleware")
+
+	entries := rec.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("got entries:\n%s", rec.Dump())
+	}
+	if len(entries[0].Fields) != 0 {
+		t.Errorf("got fields %v without request id", entries[0].Fields)
+	}
+	if fmt.Sprint(entries[1].Fields) != "[deadline_set false user <nil>]" {
+		t.Errorf("got fields %v, want the middleware run with a background context", entries[1].Fields)
+	}
+}
@@ -1,19 +1,73 @@
 package logger
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"runtime"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
 
+	"go.uber.org/multierr"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
+// InvalidLevelKey is the field key holding the level of the entries
+// logged with an unknown level, which are logged as info.
+const InvalidLevelKey = "invalid_level"
+
+// exitFunc terminates the process after a fatal entry.
+var exitFunc = os.Exit
+
+// fatalHook syncs the zap logger before exiting on fatal entries,
+// so no pending entry is lost.
+type fatalHook struct {
+	logger *zap.Logger
+}
+
+func (h *fatalHook) OnWrite(*zapcore.CheckedEntry, []zapcore.Field) {
+	if h.logger != nil {
+		_ = h.logger.Sync()
+	}
+	exitFunc(1)
+}
+
+// panicHook keeps zap from panicking right away on panic entries,
+// and on dpanic entries in development mode, for the Logger to sync
+// the writer before panicking, see Logger.flushOnPanic.
+type panicHook struct{}
+
+func (panicHook) OnWrite(*zapcore.CheckedEntry, []zapcore.Field) {}
+
 type zapLogger struct {
-	logger *zap.SugaredLogger
+	logger      *zap.SugaredLogger
+	onSyncError func(error)
+	// development when true the DPanicLevel entries panic.
+	development bool
 }
 
+// Sync flushes the zap logger. The errors returned by the outputs that
+// can't be synced, like terminals and pipes, are ignored.
 func (z zapLogger) Sync() {
-	_ = z.logger.Sync()
+	for _, err := range multierr.Errors(z.logger.Sync()) {
+		if !benignSyncError(err) {
+			z.onSyncError(err)
+		}
+	}
+}
+
+// benignSyncError returns if err is returned by syncing an
+// output that doesn't support it, e.g. a terminal or a pipe.
+func benignSyncError(err error) bool {
+	return errors.Is(err, syscall.EINVAL) || errors.Is(err, syscall.ENOTTY)
+}
+
+// printSyncError is the default Config.OnSyncError.
+func printSyncError(err error) {
+	fmt.Fprintf(os.Stderr, "logger: sync failed: %v\n", err)
 }
 
 func (z zapLogger) Log(level Level, args ...interface{}) {
@@ -26,10 +80,17 @@ func (z zapLogger) Log(level Level, args ...interface{}) {
 		z.logger.Warn(args...)
 	case ErrorLevel:
 		z.logger.Error(args...)
+	case DPanicLevel:
+		z.logger.DPanic(args...)
+		if z.development {
+			z.panic(fmt.Sprint(args...))
+		}
 	case PanicLevel:
 		z.logger.Panic(args...)
 	case FatalLevel:
 		z.logger.Fatal(args...)
+	default:
+		z.logger.With(InvalidLevelKey, int(level)).Info(args...)
 	}
 }
 
@@ -43,15 +104,153 @@ func (z zapLogger) Logf(level Level, str string, args ...interface{}) {
 		z.logger.Warnf(str, args...)
 	case ErrorLevel:
 		z.logger.Errorf(str, args...)
+	case DPanicLevel:
+		z.logger.DPanicf(str, args...)
+		if z.development {
+			if len(args) > 0 {
+				str = fmt.Sprintf(str, args...)
+			}
+			z.panic(str)
+		}
 	case PanicLevel:
 		z.logger.Panicf(str, args...)
 	case FatalLevel:
 		z.logger.Fatalf(str, args...)
+	default:
+		z.logger.With(InvalidLevelKey, int(level)).Infof(str, args...)
+	}
+}
+
+func (z zapLogger) Logw(level Level, msg string, keysAndValues ...interface{}) {
+	switch level {
+	case DebugLevel:
+		z.logger.Debugw(msg, keysAndValues...)
+	case InfoLevel:
+		z.logger.Infow(msg, keysAndValues...)
+	case WarningLevel:
+		z.logger.Warnw(msg, keysAndValues...)
+	case ErrorLevel:
+		z.logger.Errorw(msg, keysAndValues...)
+	case DPanicLevel:
+		z.logger.DPanicw(msg, keysAndValues...)
+		if z.development {
+			z.panic(msg)
+		}
+	case PanicLevel:
+		z.logger.Panicw(msg, keysAndValues...)
+	case FatalLevel:
+		z.logger.Fatalw(msg, keysAndValues...)
+	default:
+		z.logger.Infow(msg, append([]interface{}{InvalidLevelKey, int(level)}, keysAndValues...)...)
 	}
 }
 
+// panic panics with the entry message, as zap does,
+// the Logger syncing the writer before it propagates.
+func (z zapLogger) panic(msg string) {
+	panic(msg)
+}
+
 func (z zapLogger) With(fields ...interface{}) Writer {
-	return zapLogger{logger: z.logger.With(fields...)}
+	z.logger = z.logger.With(fields...)
+	return z
+}
+
+// WithCallerSkip returns a writer skipping n additional
+// stack frames to report the caller of the entries.
+func (z zapLogger) WithCallerSkip(n int) Writer {
+	z.logger = z.logger.WithOptions(zap.AddCallerSkip(n))
+	return z
+}
+
+// WithTime returns a writer timestamping the entries with t.
+func (z zapLogger) WithTime(t time.Time) Writer {
+	z.logger = z.logger.WithOptions(zap.WithClock(zapClock{fixedClock(t)}))
+	return z
+}
+
+// defaultInitialFields returns the fields added to every entry
+// of the zap writer, in both development and production modes.
+func defaultInitialFields() map[string]interface{} {
+	fields := map[string]interface{}{
+		"goVersion": runtime.Version(),
+		"pid":       os.Getpid(),
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		fields["hostname"] = hostname
+	}
+	return fields
+}
+
+// setTimeEncoding sets the timestamp key and encoder, and the
+// duration encoder of the config.
+func setTimeEncoding(ec *zapcore.EncoderConfig, conf Config) {
+	if conf.TimeKey != "" {
+		ec.TimeKey = conf.TimeKey
+	}
+	if conf.TimeFormat != "" {
+		ec.EncodeTime = timeEncoder(conf.TimeFormat)
+	}
+	ec.EncodeDuration = zapcore.MillisDurationEncoder
+	if enc, ok := durationEncoders[conf.DurationFormat]; ok {
+		ec.EncodeDuration = enc
+	}
+}
+
+// durationEncoders are the encoders of the Config DurationFormat values.
+var durationEncoders = map[string]zapcore.DurationEncoder{
+	"millis":  zapcore.MillisDurationEncoder,
+	"seconds": zapcore.SecondsDurationEncoder,
+	"nanos":   zapcore.NanosDurationEncoder,
+	"string":  zapcore.StringDurationEncoder,
+}
+
+// setFieldKeys overrides the field keys of the config.
+func setFieldKeys(ec *zapcore.EncoderConfig, keys FieldKeys) {
+	for _, k := range []struct {
+		key  *string
+		with string
+	}{
+		{&ec.MessageKey, keys.MessageKey},
+		{&ec.LevelKey, keys.LevelKey},
+		{&ec.TimeKey, keys.TimeKey},
+		{&ec.CallerKey, keys.CallerKey},
+		{&ec.StacktraceKey, keys.StacktraceKey},
+		{&ec.NameKey, keys.NameKey},
+	} {
+		switch k.with {
+		case "":
+		case "-":
+			*k.key = zapcore.OmitKey
+		default:
+			*k.key = k.with
+		}
+	}
+}
+
+// timeEncoder returns the encoder of a Config.TimeFormat.
+func timeEncoder(format string) zapcore.TimeEncoder {
+	switch strings.ToLower(format) {
+	case "iso8601":
+		return zapcore.ISO8601TimeEncoder
+	case "rfc3339":
+		return zapcore.RFC3339TimeEncoder
+	case "rfc3339nano":
+		return zapcore.RFC3339NanoTimeEncoder
+	case "epoch":
+		return zapcore.EpochTimeEncoder
+	case "epochmillis":
+		return epochMillisTimeEncoder
+	case "epochnanos":
+		return zapcore.EpochNanosTimeEncoder
+	}
+	return zapcore.TimeEncoderOfLayout(format)
+}
+
+// epochMillisTimeEncoder encodes the time as the integer
+// number of milliseconds since the Unix epoch.
+func epochMillisTimeEncoder(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+	enc.AppendInt64(t.UnixNano() / int64(time.Millisecond))
 }
 
 // NewZapLogger creates a new logger based on Zap.
@@ -61,46 +260,85 @@ func NewZapLogger(conf Config) (Logger, error) {
 }
 
 // newZapLogger returns a new zap writer.
-func newZapLogger(conf Config, callerSkip int) (Writer, error) {
+func newZapLogger(conf Config, callerSkip int, level *levelVar) (Writer, error) {
 	callerSkip++
-	if conf.Log == "Dev" {
+	onSyncError := conf.OnSyncError
+	if onSyncError == nil {
+		onSyncError = printSyncError
+	}
+	hook := &fatalHook{}
+	opts := []zap.Option{
+		zap.AddCallerSkip(callerSkip),
+		zap.WithFatalHook(hook),
+		zap.WithPanicHook(panicHook{}),
+	}
+	if conf.Clock != nil {
+		opts = append(opts, zap.WithClock(zapClock{conf.Clock}))
+	}
+	if conf.StacktraceLevel != nil && !conf.DisableStacktrace {
+		opts = append(opts, zap.AddStacktrace(zapLevel(*conf.StacktraceLevel)))
+	}
+
+	errorOutputPaths := conf.ErrorOutputPaths
+	if len(errorOutputPaths) == 0 {
+		errorOutputPaths = []string{"stderr"}
+	}
+
+	initFields := make(map[string]interface{}, len(conf.InitialFields))
+	if !conf.DisableInitialFields {
+		initFields = defaultInitialFields()
+	}
+	for k, v := range conf.InitialFields {
+		initFields[k] = v
+	}
+	if conf.development() {
 		config := zap.NewDevelopmentConfig()
+		config.InitialFields = initFields
+		config.Level = level.zap
 		config.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+		if conf.Encoding == "json" {
+			config.Encoding = "json"
+			config.EncoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+		}
 		config.DisableStacktrace = conf.DisableStacktrace
-		if conf.OutputPaths != nil {
+		config.DisableCaller = conf.DisableCaller
+		config.ErrorOutputPaths = errorOutputPaths
+		setTimeEncoding(&config.EncoderConfig, conf)
+		setFieldKeys(&config.EncoderConfig, conf.FieldKeys)
+		if len(conf.OutputPaths) > 0 {
 			config.OutputPaths = conf.OutputPaths
 		}
 
-		logger, err := config.Build()
+		logger, err := buildZap(config, conf, opts...)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("building logger with outputs %v: %w", conf.outputPaths(config.OutputPaths), err)
 		}
+		hook.logger = logger
 
 		return zapLogger{
-			logger: logger.WithOptions(zap.AddCallerSkip(callerSkip)).Sugar(),
+			logger:      logger.Sugar(),
+			onSyncError: onSyncError,
+			development: true,
 		}, nil
 	}
 
-	initFields := map[string]interface{}{
-		"goVersion": runtime.Version(),
-		"pid":       os.Getpid(),
-	}
-	hostname, err := os.Hostname()
-	if err == nil {
-		initFields["hostname"] = hostname
-	}
-
 	outputPaths := conf.OutputPaths
-	if outputPaths == nil {
+	if len(outputPaths) == 0 {
 		outputPaths = []string{"stdout"}
 	}
 
+	encoding := conf.Encoding
+	if encoding == "" {
+		encoding = "json"
+	}
 	cfg := zap.Config{
-		Encoding:          "json",
-		Level:             zap.NewAtomicLevelAt(zapcore.DebugLevel),
+		Encoding:          encoding,
+		Level:             level.zap,
 		OutputPaths:       outputPaths,
+		ErrorOutputPaths:  errorOutputPaths,
 		InitialFields:     initFields,
 		DisableStacktrace: conf.DisableStacktrace,
+		DisableCaller:     conf.DisableCaller,
 		EncoderConfig: zapcore.EncoderConfig{
 			TimeKey:        "ts",
 			LevelKey:       "level",
@@ -116,12 +354,69 @@ func newZapLogger(conf Config, callerSkip int) (Writer, error) {
 		},
 	}
 
-	logger, err := cfg.Build()
+	setTimeEncoding(&cfg.EncoderConfig, conf)
+	setFieldKeys(&cfg.EncoderConfig, conf.FieldKeys)
+
+	logger, err := buildZap(cfg, conf, opts...)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("building logger with outputs %v: %w", conf.outputPaths(cfg.OutputPaths), err)
 	}
+	hook.logger = logger
 
 	return zapLogger{
-		logger: logger.WithOptions(zap.AddCallerSkip(callerSkip)).Sugar(),
+		logger:      logger.Sugar(),
+		onSyncError: onSyncError,
 	}, nil
 }
+
+// buildZap builds the zap logger, masking the encoded entries when
+// the output masking is enabled, with a core per output when the
+// outputs have their own level, and sampling the entries.
+func buildZap(zcfg zap.Config, conf Config, opts ...zap.Option) (*zap.Logger, error) {
+	if !conf.MaskOutput && len(conf.Outputs) == 0 {
+		return zcfg.Build(append(opts, conf.samplingOptions()...)...)
+	}
+
+	var enc zapcore.Encoder
+	if zcfg.Encoding == "console" {
+		enc = zapcore.NewConsoleEncoder(zcfg.EncoderConfig)
+	} else {
+		enc = zapcore.NewJSONEncoder(zcfg.EncoderConfig)
+	}
+	if conf.MaskOutput {
+		enc = maskingEncoder{Encoder: enc, masker: conf.masker()}
+	}
+
+	cores, closeAll, err := outputCores(zcfg, conf.Outputs, enc)
+	if err != nil {
+		return nil, err
+	}
+
+	// the core built by zap is replaced by the ones built here, so the
+	// initial fields are added afterwards to not be lost with it.
+	opts = append(opts, zap.WrapCore(func(zapcore.Core) zapcore.Core {
+		return zapcore.NewTee(cores...)
+	}))
+	if len(zcfg.InitialFields) > 0 {
+		keys := make([]string, 0, len(zcfg.InitialFields))
+		for k := range zcfg.InitialFields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		fields := make([]zap.Field, 0, len(keys))
+		for _, k := range keys {
+			fields = append(fields, zap.Any(k, zcfg.InitialFields[k]))
+		}
+		opts = append(opts, zap.Fields(fields...))
+	}
+	opts = append(opts, conf.samplingOptions()...)
+	zcfg.OutputPaths = nil
+	zcfg.InitialFields = nil
+
+	logger, err := zcfg.Build(opts...)
+	if err != nil {
+		closeAll()
+		return nil, err
+	}
+	return logger, nil
+}
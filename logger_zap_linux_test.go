@@ -0,0 +1,78 @@
+//go:build linux
+
+package logger
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// syncErrors returns the errors reported when syncing a zap writer to f.
+func syncErrors(t *testing.T, f *os.File) []error {
+	var errs []error
+	w := zapLogger{
+		logger: zap.New(zapcore.NewCore(
+			zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()),
+			zapcore.AddSync(f), zapcore.DebugLevel), zap.ErrorOutput(zapcore.AddSync(io.Discard))).Sugar(),
+		onSyncError: func(err error) { errs = append(errs, err) },
+	}
+	NewWithWriter(Config{}, w).Info("entry")
+	w.Sync()
+	return errs
+}
+
+func TestSyncPipe(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	go func() { _, _ = r.Read(make([]byte, 4096)) }()
+	if errs := syncErrors(t, w); len(errs) > 0 {
+		t.Errorf("got errors syncing a pipe: %v", errs)
+	}
+}
+
+func TestSyncDevice(t *testing.T) {
+	f, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if errs := syncErrors(t, f); len(errs) > 0 {
+		t.Errorf("got errors syncing %s: %v", os.DevNull, errs)
+	}
+}
+
+func TestSyncClosedFile(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "closed.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	errs := syncErrors(t, f)
+	if len(errs) != 1 || !errors.Is(errs[0], os.ErrClosed) {
+		t.Fatalf("got errors %v, want the closed file one", errs)
+	}
+}
+
+func TestSyncFile(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "out.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if errs := syncErrors(t, f); len(errs) > 0 {
+		t.Errorf("got errors syncing a file: %v", errs)
+	}
+	if b, _ := os.ReadFile(f.Name()); len(b) == 0 {
+		t.Errorf("the entry was not written")
+	}
+}
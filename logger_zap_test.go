@@ -0,0 +1,615 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// memorySink is an in-memory zap sink, see newMemorySink.
+type memorySink struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+	err error // returned by Write when set
+}
+
+func (s *memorySink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return 0, s.err
+	}
+	return s.buf.Write(p)
+}
+
+func (s *memorySink) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buf.String()
+}
+
+func (*memorySink) Sync() error  { return nil }
+func (*memorySink) Close() error { return nil }
+
+var (
+	registerMemorySink sync.Once
+	memorySinks        sync.Map
+	memorySinkID       atomic.Int64
+)
+
+// newMemorySink returns the output path of a new in-memory sink.
+func newMemorySink(t testing.TB) (string, *memorySink) {
+	registerMemorySink.Do(func() {
+		err := zap.RegisterSink("memory", func(u *url.URL) (zap.Sink, error) {
+			s, ok := memorySinks.Load(u.Host)
+			if !ok {
+				return nil, fmt.Errorf("unknown memory sink %s", u.Host)
+			}
+			return s.(*memorySink), nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+	name := fmt.Sprintf("sink%d", memorySinkID.Add(1))
+	s := &memorySink{}
+	memorySinks.Store(name, s)
+	return "memory://" + name, s
+}
+
+func TestDevelopmentLevel(t *testing.T) {
+	path, sink := newMemorySink(t)
+	lg, err := New(Config{Mode: ModeDevelopment, Level: InfoLevel, OutputPaths: []string{path}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lg.Debug("hidden debug entry")
+	lg.Info("visible info entry")
+	lg.Sync()
+
+	out := sink.String()
+	if strings.Contains(out, "hidden") || !strings.Contains(out, "visible") {
+		t.Errorf("got output:\n%s", out)
+	}
+	if lg.writer.(zapLogger).logger.Desugar().Core().Enabled(zapcore.DebugLevel) {
+		t.Errorf("the zap core is enabled at debug level")
+	}
+}
+
+func TestZapCaller(t *testing.T) {
+	path, sink := newMemorySink(t)
+	lg, err := New(Config{OutputPaths: []string{path}, DisableInitialFields: true, DisableStacktrace: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := []func(Logger){
+		func(l Logger) { l.Debug("m") },
+		func(l Logger) { l.Infof("m") },
+		func(l Logger) { l.Warn("m") },
+		func(l Logger) { l.Errorf("m %d", 1) },
+		func(l Logger) { l.Log(InfoLevel, "m") },
+		func(l Logger) { l.Logf(InfoLevel, "m %d", 1) },
+		func(l Logger) { l.Logw(InfoLevel, "m", "k", 1) },
+		func(l Logger) { l.Cond(true, InfoLevel, ErrorLevel, "m") },
+		func(l Logger) { l.Condf(false, InfoLevel, ErrorLevel, "m %d", 1) },
+		func(l Logger) { l.Debugw("m", "k", 1) },
+		func(l Logger) { l.Infow("m", "k", 1) },
+		func(l Logger) { l.Warnw("m", "k", 1) },
+		func(l Logger) { l.Errorw("m", "k", 1) },
+	}
+	for _, call := range calls {
+		call(lg)
+		call(lg.With("child", true))
+	}
+
+	lines := strings.Split(strings.TrimSpace(sink.String()), "\n")
+	if len(lines) != 2*len(calls) {
+		t.Fatalf("got %d entries, want %d", len(lines), 2*len(calls))
+	}
+	for i, line := range lines {
+		if !strings.Contains(line, `/logger_zap_test.go:`) {
+			t.Errorf("call %d: got %s, want the test file as caller", i/2, line)
+		}
+	}
+}
+
+func TestCallerSkip(t *testing.T) {
+	path, sink := newMemorySink(t)
+	lg, err := New(Config{OutputPaths: []string{path}, DisableInitialFields: true, CallerSkip: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	appLogger{lg}.info("config skip")
+	appLogger{lg.With("k", 1)}.info("derived logger")
+
+	path2, sink2 := newMemorySink(t)
+	lg2, err := New(Config{OutputPaths: []string{path2}, DisableInitialFields: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	appLogger{lg2.WithCallerSkip(1)}.info("logger skip")
+	lg2.WithCallerSkip(1).WithCallerSkip(-1).Info("no skip")
+	appLogger{lg2}.info("helper caller")
+
+	lines := strings.Split(strings.TrimSpace(sink.String()+sink2.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("got %d entries:\n%s", len(lines), strings.Join(lines, "\n"))
+	}
+	for _, line := range lines {
+		var e struct{ Msg, Caller string }
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatal(err)
+		}
+		want := "/logger_zap_test.go:"
+		if e.Msg == "helper caller" {
+			want = "/app_logger_test.go:"
+		}
+		if !strings.Contains(e.Caller, want) {
+			t.Errorf("%s: got caller %q, want %s", e.Msg, e.Caller, want)
+		}
+	}
+}
+
+func TestStacktraceLevel(t *testing.T) {
+	errorLevel, warningLevel := ErrorLevel, WarningLevel
+	tests := []struct {
+		name           string
+		conf           Config
+		warning, error bool
+	}{
+		{"production", Config{}, false, true},
+		{"development", Config{Mode: ModeDevelopment, Encoding: "json"}, true, true},
+		{"development error level", Config{Mode: ModeDevelopment, Encoding: "json", StacktraceLevel: &errorLevel}, false, true},
+		{"production warning level", Config{StacktraceLevel: &warningLevel}, true, true},
+		{"disabled", Config{StacktraceLevel: &warningLevel, DisableStacktrace: true}, false, false},
+	}
+	for _, tt := range tests {
+		path, sink := newMemorySink(t)
+		tt.conf.OutputPaths = []string{path}
+		tt.conf.DisableInitialFields = true
+		tt.conf.FieldKeys = FieldKeys{MessageKey: "msg", StacktraceKey: "stacktrace"}
+		lg, err := New(tt.conf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lg.Warn("warning")
+		lg.Error("error")
+
+		lines := strings.Split(strings.TrimSpace(sink.String()), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("%s: got output:\n%s", tt.name, sink.String())
+		}
+		for i, want := range []bool{tt.warning, tt.error} {
+			var e map[string]interface{}
+			if err := json.Unmarshal([]byte(lines[i]), &e); err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := e["stacktrace"]; ok != want {
+				t.Errorf("%s: %s entry: got stacktrace %v, want %v", tt.name, e["msg"], ok, want)
+			}
+		}
+	}
+}
+
+func TestDurationFormat(t *testing.T) {
+	d := 1500 * time.Millisecond
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"", `"elapsed":1500`},
+		{"millis", `"elapsed":1500`},
+		{"seconds", `"elapsed":1.5`},
+		{"nanos", `"elapsed":1500000000`},
+		{"string", `"elapsed":"1.5s"`},
+	}
+	for _, mode := range []Mode{ModeProduction, ModeDevelopment} {
+		for _, tt := range tests {
+			path := filepath.Join(t.TempDir(), "app.log")
+			lg, err := New(Config{Mode: mode, Encoding: "json", DurationFormat: tt.format, OutputPaths: []string{path}, DisableInitialFields: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			lg.Infow("done", "elapsed", d)
+			lg.With("elapsed", d).Info("done")
+			lg.Sync()
+
+			b, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+			if len(lines) != 2 {
+				t.Fatalf("%s %q: got output:\n%s", mode, tt.format, b)
+			}
+			for _, line := range lines {
+				if !strings.Contains(line, tt.want) {
+					t.Errorf("%s %q: got %s, want %s", mode, tt.format, line, tt.want)
+				}
+			}
+		}
+	}
+}
+
+func TestLogfVerbatim(t *testing.T) {
+	tests := []struct {
+		format string
+		args   []interface{}
+		want   string
+	}{
+		{"select * where name like 'a%s'", nil, "select * where name like 'a%s'"},
+		{"100%% done", nil, "100%% done"},
+		{"bad %z verb %", nil, "bad %z verb %"},
+		{"%s done", []interface{}{"job"}, "job done"},
+		{"100%% of %s", []interface{}{"job"}, "100% of job"},
+		{"bad %z verb", []interface{}{1}, "bad %!z(int=1) verb"},
+		{"%s and %s", []interface{}{"a"}, "a and %!s(MISSING)"},
+	}
+	path, sink := newMemorySink(t)
+	lg, err := New(Config{OutputPaths: []string{path}, DisableInitialFields: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := NewRecorder()
+	for _, tt := range tests {
+		lg.Infof(tt.format, tt.args...)
+		NewWithWriter(Config{}, rec).Infof(tt.format, tt.args...)
+	}
+	lg.Sync()
+
+	lines := strings.Split(strings.TrimSpace(sink.String()), "\n")
+	entries := rec.Entries()
+	if len(lines) != len(tests) || len(entries) != len(tests) {
+		t.Fatalf("got %d zap entries and %d recorded entries", len(lines), len(entries))
+	}
+	for i, tt := range tests {
+		var e struct{ Msg string }
+		if err := json.Unmarshal([]byte(lines[i]), &e); err != nil {
+			t.Fatal(err)
+		}
+		if e.Msg != tt.want {
+			t.Errorf("%q %v: zap wrote %q, want %q", tt.format, tt.args, e.Msg, tt.want)
+		}
+		if got := entries[i].Message(); got != tt.want {
+			t.Errorf("%q %v: recorded %q, want %q", tt.format, tt.args, got, tt.want)
+		}
+		if len(tt.args) == 0 && (entries[i].Str != tt.format || len(entries[i].Args) != 0) {
+			t.Errorf("%q: got recorded entry %+v, want the literal message", tt.format, entries[i])
+		}
+	}
+}
+
+func TestZapCoreLevel(t *testing.T) {
+	path, sink := newMemorySink(t)
+	lg, err := New(Config{Level: InfoLevel, OutputPaths: []string{path}, DisableInitialFields: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// writing to the zap writer directly bypasses the Logger level check.
+	lg.writer.Log(DebugLevel, "dropped by zap")
+	lg.writer.Logf(DebugLevel, "dropped by %s", "zap")
+	lg.writer.(WriterW).Logw(DebugLevel, "dropped by zap", "k", 1)
+	lg.writer.Log(InfoLevel, "written at info")
+	lg.SetLevel(DebugLevel)
+	lg.writer.Log(DebugLevel, "written after SetLevel")
+	lg.Sync()
+
+	out := sink.String()
+	if strings.Contains(out, "dropped") {
+		t.Errorf("got debug entries written by the zap core at info level:\n%s", out)
+	}
+	if !strings.Contains(out, "written at info") || !strings.Contains(out, "written after SetLevel") {
+		t.Errorf("got output:\n%s", out)
+	}
+	if !lg.Enabled(DebugLevel) {
+		t.Error("the Logger level is not the zap one")
+	}
+}
+
+func TestTimeFormat(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 30, 15, 123456789, time.UTC)
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"", "2024-05-01T12:30:15.123Z"},
+		{"iso8601", "2024-05-01T12:30:15.123Z"},
+		{"RFC3339", "2024-05-01T12:30:15Z"},
+		{"rfc3339nano", "2024-05-01T12:30:15.123456789Z"},
+		{"epoch", "1714566615.1234567"},
+		{"epochmillis", "1714566615123"},
+		{"epochnanos", "1714566615123456789"},
+		{"02/01/2006 15:04", "01/05/2024 12:30"},
+	}
+	for _, mode := range []Mode{ModeProduction, ModeDevelopment} {
+		for _, tt := range tests {
+			path := filepath.Join(t.TempDir(), "app.log")
+			lg, err := New(Config{
+				Mode:        mode,
+				Encoding:    "json",
+				TimeFormat:  tt.format,
+				TimeKey:     "timestamp",
+				Clock:       NewManualClock(at),
+				OutputPaths: []string{path},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			lg.Info("timed")
+			lg.Sync()
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dec := json.NewDecoder(bytes.NewReader(data))
+			dec.UseNumber()
+			var entry map[string]interface{}
+			if err := dec.Decode(&entry); err != nil {
+				t.Fatalf("%s %q: decoding %s: %v", mode, tt.format, data, err)
+			}
+			if _, ok := entry["ts"]; ok {
+				t.Errorf("%s %q: ts key still written", mode, tt.format)
+			}
+			if got := fmt.Sprint(entry["timestamp"]); got != tt.want {
+				t.Errorf("%s %q: got timestamp %s, want %s", mode, tt.format, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestEncoding(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		json    bool
+		level   string
+		dpanics bool
+	}{
+		{"production", Config{}, true, `"level":"info"`, false},
+		{"production json", Config{Encoding: "json"}, true, `"level":"info"`, false},
+		{"production console", Config{Encoding: "console"}, false, "\tinfo\t", false},
+		{"development", Config{Mode: ModeDevelopment}, false, "\t\x1b[34mINFO\x1b[0m\t", true},
+		{"development console", Config{Mode: ModeDevelopment, Encoding: "console"}, false, "\t\x1b[34mINFO\x1b[0m\t", true},
+		{"development json", Config{Mode: ModeDevelopment, Encoding: "json"}, true, `"L":"INFO"`, true},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "app.log")
+		tt.cfg.OutputPaths = []string{path}
+		lg, err := New(tt.cfg)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		lg.Infow("encoded", "k", 1)
+		dpanicked := recoverPanic(func() { lg.DPanic("dpanic") }) != nil
+		lg.Sync()
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		line := strings.SplitN(string(data), "\n", 2)[0]
+		var entry map[string]interface{}
+		if isJSON := json.Unmarshal([]byte(line), &entry) == nil; isJSON != tt.json {
+			t.Errorf("%s: got JSON %v for %q", tt.name, isJSON, line)
+		}
+		if !strings.Contains(line, tt.level) {
+			t.Errorf("%s: got %q, want the level as %q", tt.name, line, tt.level)
+		}
+		if dpanicked != tt.dpanics {
+			t.Errorf("%s: got DPanic panicking %v", tt.name, dpanicked)
+		}
+	}
+
+	if _, err := New(Config{Encoding: "text"}); err == nil || !strings.Contains(err.Error(), `unknown encoding "text"`) {
+		t.Errorf("got error %v for an unknown encoding", err)
+	}
+}
+
+// decodeEntries returns the JSON entries written to the file at path.
+func decodeEntries(t *testing.T, path string) []map[string]interface{} {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries []map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for dec.More() {
+		var e map[string]interface{}
+		if err := dec.Decode(&e); err != nil {
+			t.Fatalf("decoding %s: %v", data, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// entryKeys returns the sorted keys of an entry.
+func entryKeys(e map[string]interface{}) string {
+	keys := make([]string, 0, len(e))
+	for k := range e {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+func TestFieldKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		want string
+	}{
+		{"default", Config{}, "caller,level,msg,stacktrace,ts"},
+		{"renamed", Config{FieldKeys: FieldKeys{
+			MessageKey: "message", LevelKey: "severity", TimeKey: "@timestamp",
+			CallerKey: "source", StacktraceKey: "trace",
+		}}, "@timestamp,message,severity,source,trace"},
+		{"dropped", Config{FieldKeys: FieldKeys{CallerKey: "-", StacktraceKey: "-", TimeKey: "-"}}, "level,msg"},
+		{"time key precedence", Config{TimeKey: "time", FieldKeys: FieldKeys{TimeKey: "timestamp"}}, "caller,level,msg,stacktrace,timestamp"},
+		{"development", Config{Mode: ModeDevelopment, Encoding: "json", FieldKeys: FieldKeys{
+			MessageKey: "message", LevelKey: "severity", CallerKey: "-",
+		}}, "S,T,message,severity"},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "app.log")
+		tt.cfg.OutputPaths = []string{path}
+		tt.cfg.DisableInitialFields = true
+		lg, err := New(tt.cfg)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		lg.Error("failed")
+		lg.Sync()
+
+		entries := decodeEntries(t, path)
+		if len(entries) != 1 {
+			t.Fatalf("%s: got %d entries", tt.name, len(entries))
+		}
+		if got := entryKeys(entries[0]); got != tt.want {
+			t.Errorf("%s: got keys %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestInitialFields(t *testing.T) {
+	fields := map[string]interface{}{"service": "api", "env": "prod", "pid": "overridden"}
+	for _, mode := range []Mode{ModeProduction, ModeDevelopment} {
+		path := filepath.Join(t.TempDir(), "app.log")
+		lg, err := New(Config{Mode: mode, Encoding: "json", InitialFields: fields, OutputPaths: []string{path}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		lg.Info("first")
+		lg.With("k", 1).Info("second")
+		lg.Sync()
+
+		entries := decodeEntries(t, path)
+		if len(entries) != 2 {
+			t.Fatalf("%s: got %d entries", mode, len(entries))
+		}
+		for _, e := range entries {
+			if e["service"] != "api" || e["env"] != "prod" || e["pid"] != "overridden" {
+				t.Errorf("%s: got entry %v, want the initial fields, the user ones winning", mode, e)
+			}
+			if e["goVersion"] != runtime.Version() {
+				t.Errorf("%s: got entry %v, want the built-in fields kept", mode, e)
+			}
+		}
+	}
+
+	rec := NewRecorder()
+	NewWithWriter(Config{InitialFields: fields}, rec).With("k", 1).Info("recorded")
+	e, _ := rec.LastEntry()
+	if got := fmt.Sprint(e.Fields); got != "[env prod pid overridden service api k 1]" {
+		t.Errorf("got recorded fields %s", got)
+	}
+	if _, ok := e.Field("goVersion"); ok {
+		t.Error("the built-in fields were added to a custom writer")
+	}
+}
+
+func TestDisableCaller(t *testing.T) {
+	tests := []struct {
+		name          string
+		cfg           Config
+		callerKey     string
+		stacktraceKey string
+	}{
+		{"production", Config{}, "caller", "stacktrace"},
+		{"development", Config{Mode: ModeDevelopment, Encoding: "json"}, "C", "S"},
+	}
+	for _, tt := range tests {
+		for _, disable := range []bool{false, true} {
+			path := filepath.Join(t.TempDir(), "app.log")
+			cfg := tt.cfg
+			cfg.OutputPaths = []string{path}
+			cfg.DisableCaller = disable
+			lg, err := New(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lg.Error("failed")
+			lg.Sync()
+
+			e := decodeEntries(t, path)[0]
+			if _, ok := e[tt.callerKey]; ok == disable {
+				t.Errorf("%s, disable caller %v: got entry %v", tt.name, disable, e)
+			}
+			if _, ok := e[tt.stacktraceKey]; !ok {
+				t.Errorf("%s, disable caller %v: got entry %v without stack trace", tt.name, disable, e)
+			}
+		}
+	}
+}
+
+func TestErrorOutputPaths(t *testing.T) {
+	for _, mode := range []Mode{ModeProduction, ModeDevelopment} {
+		path, sink := newMemorySink(t)
+		sink.err = errors.New("disk full")
+		errPath := filepath.Join(t.TempDir(), "errors.log")
+		lg, err := New(Config{Mode: mode, OutputPaths: []string{path}, ErrorOutputPaths: []string{errPath}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		lg.Info("lost")
+		lg.Sync()
+
+		data, err := os.ReadFile(errPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), "write error: disk full") {
+			t.Errorf("%s: got error output %q", mode, data)
+		}
+	}
+}
+
+func TestDisableInitialFields(t *testing.T) {
+	for _, mode := range []Mode{ModeProduction, ModeDevelopment} {
+		for _, disable := range []bool{false, true} {
+			path := filepath.Join(t.TempDir(), "app.log")
+			lg, err := New(Config{
+				Mode:                 mode,
+				Encoding:             "json",
+				OutputPaths:          []string{path},
+				DisableInitialFields: disable,
+				InitialFields:        map[string]interface{}{"service": "api"},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			lg.Info("entry")
+			lg.Sync()
+
+			e := decodeEntries(t, path)[0]
+			builtin := []string{"goVersion", "pid"}
+			if _, err := os.Hostname(); err == nil {
+				builtin = append(builtin, "hostname")
+			}
+			for _, k := range builtin {
+				if _, ok := e[k]; ok == disable {
+					t.Errorf("%s, disable initial fields %v: got entry %v", mode, disable, e)
+				}
+			}
+			if e["service"] != "api" {
+				t.Errorf("%s, disable initial fields %v: got entry %v without the user fields", mode, disable, e)
+			}
+		}
+	}
+}
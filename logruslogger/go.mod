@@ -0,0 +1,17 @@
+module github.com/Aibier/go-logger/logruslogger
+
+go 1.23
+
+require (
+	github.com/Aibier/go-logger v0.0.0
+	github.com/sirupsen/logrus v1.10.2
+)
+
+require (
+	go.uber.org/multierr v1.10.0 // indirect
+	go.uber.org/zap v1.27.0 // indirect
+	golang.org/x/sys v0.13.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/Aibier/go-logger => ../
@@ -0,0 +1,81 @@
+// Package logruslogger forwards the github.com/sirupsen/logrus entries
+// to the github.com/Aibier/go-logger Logger.
+package logruslogger
+
+import (
+	"sort"
+
+	logger "github.com/Aibier/go-logger"
+	"github.com/sirupsen/logrus"
+)
+
+// ForwardHook is a logrus.Hook logging the logrus entries through a Logger.
+type ForwardHook struct {
+	l logger.Logger
+}
+
+var _ logrus.Hook = ForwardHook{}
+
+// NewLogrusForwardHook returns a hook forwarding the entries of every
+// level to l. Discarding the logrus output routes everything through
+// the Logger writers:
+//
+//	logrus.AddHook(logruslogger.NewLogrusForwardHook(lg))
+//	logrus.SetOutput(io.Discard)
+//
+// The logrus trace entries are logged as debug, and the panic and fatal
+// entries as error, logrus panicking or exiting by itself after the hooks
+// ran. The fields are forwarded, the logrus.ErrorKey one through
+// Logger.WithError. The entries keep the time of the logrus entries,
+// see Logger.WithTime.
+func NewLogrusForwardHook(l logger.Logger) logrus.Hook {
+	return ForwardHook{l: l}
+}
+
+// Levels implements logrus.Hook.
+func (h ForwardHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire implements logrus.Hook.
+func (h ForwardHook) Fire(entry *logrus.Entry) error {
+	l := h.l.WithTime(entry.Time)
+	keys := make([]string, 0, len(entry.Data))
+	for k := range entry.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	fields := make([]interface{}, 0, 2*len(keys))
+	for _, k := range keys {
+		v := entry.Data[k]
+		if err, ok := v.(error); ok && k == logrus.ErrorKey {
+			l = l.WithError(err)
+			continue
+		}
+		fields = append(fields, k, v)
+	}
+	if len(fields) > 0 {
+		l = l.With(fields...)
+	}
+
+	level := Level(entry.Level)
+	l.Log(level, entry.Message)
+	if entry.Level <= logrus.FatalLevel {
+		l.Sync()
+	}
+	return nil
+}
+
+// Level returns the Logger level of a logrus level.
+func Level(level logrus.Level) logger.Level {
+	switch level {
+	case logrus.TraceLevel, logrus.DebugLevel:
+		return logger.DebugLevel
+	case logrus.InfoLevel:
+		return logger.InfoLevel
+	case logrus.WarnLevel:
+		return logger.WarningLevel
+	default:
+		return logger.ErrorLevel
+	}
+}
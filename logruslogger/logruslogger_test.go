@@ -0,0 +1,91 @@
+package logruslogger
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	logger "github.com/Aibier/go-logger"
+	"github.com/sirupsen/logrus"
+)
+
+// newLogrus returns a logrus logger forwarding its entries to rec.
+func newLogrus(rec *logger.Recorder) *logrus.Logger {
+	lr := logrus.New()
+	lr.SetOutput(io.Discard)
+	lr.SetLevel(logrus.TraceLevel)
+	lr.AddHook(NewLogrusForwardHook(logger.NewWithWriter(logger.Config{Level: logger.DebugLevel}, rec)))
+	return lr
+}
+
+func TestForwardHook(t *testing.T) {
+	rec := logger.NewRecorder()
+	lr := newLogrus(rec)
+	failure := errors.New("boom")
+	lr.WithFields(logrus.Fields{"user": "ann", "attempt": 2}).Info("login")
+	lr.WithError(failure).Error("failed")
+	lr.Trace("trace")
+	lr.Warn("warn")
+
+	entries := rec.Entries()
+	if len(entries) != 4 {
+		t.Fatalf("got %d entries:\n%s", len(entries), rec.Dump())
+	}
+	for i, want := range []logger.Level{logger.InfoLevel, logger.ErrorLevel, logger.DebugLevel, logger.WarningLevel} {
+		if entries[i].Level != want {
+			t.Errorf("%s: got level %v, want %v", entries[i].Message(), entries[i].Level, want)
+		}
+	}
+	if fields := entries[0].FieldMap(); fields["user"] != "ann" || fields["attempt"] != 2 {
+		t.Errorf("got fields %v", fields)
+	}
+	if v, _ := entries[1].Field("error"); v != failure.Error() {
+		t.Errorf("got error %v", v)
+	}
+
+	rec.Reset()
+	func() {
+		defer func() { recover() }()
+		lr.Panic("panic")
+	}()
+	if e, ok := rec.LastEntry(); !ok || e.Level != logger.ErrorLevel || e.Message() != "panic" {
+		t.Errorf("got entry %v, want the panic one as an error", e)
+	}
+}
+
+func TestForwardHookTime(t *testing.T) {
+	rec := logger.NewRecorder()
+	rec.SetClock(logger.NewManualClock(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)))
+	lr := newLogrus(rec)
+	ts := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	lr.WithTime(ts).Info("past")
+	lr.Info("now")
+
+	entries := rec.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries", len(entries))
+	}
+	if !entries[0].Time.Equal(ts) {
+		t.Errorf("got time %v, want the logrus entry one %v", entries[0].Time, ts)
+	}
+	if since := time.Since(entries[1].Time); since < 0 || since > time.Minute {
+		t.Errorf("got time %v, want the logrus entry one, now", entries[1].Time)
+	}
+}
+
+func TestLevel(t *testing.T) {
+	for level, want := range map[logrus.Level]logger.Level{
+		logrus.TraceLevel: logger.DebugLevel,
+		logrus.DebugLevel: logger.DebugLevel,
+		logrus.InfoLevel:  logger.InfoLevel,
+		logrus.WarnLevel:  logger.WarningLevel,
+		logrus.ErrorLevel: logger.ErrorLevel,
+		logrus.FatalLevel: logger.ErrorLevel,
+		logrus.PanicLevel: logger.ErrorLevel,
+	} {
+		if got := Level(level); got != want {
+			t.Errorf("%v: got %v, want %v", level, got, want)
+		}
+	}
+}
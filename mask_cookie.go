@@ -0,0 +1,57 @@
+package logger
+
+import (
+	"bytes"
+	"regexp"
+)
+
+var (
+	patternCookie          = regexp.MustCompile(`(?i)\b(?:set-)?cookie:[^\r\n"]*(?:\r?\n[ \t]+[^\r\n"]*)*`)
+	patternCookieSeparator = regexp.MustCompile(`;|\r?\n[ \t]+`)
+)
+
+// MaskCookies returns a rule masking the cookie values of the Cookie and
+// Set-Cookie headers, including folded ones. Cookie names and Set-Cookie
+// attributes, e.g. Path or HttpOnly, are preserved.
+func MaskCookies() MaskRule {
+	return MaskRule{
+		Name:        "cookie",
+		Pattern:     patternCookie,
+		ReplaceFunc: maskCookies,
+		Literals:    []string{"cookie:"},
+	}
+}
+
+// maskCookies masks the values of a Cookie header, or only the first
+// one of a Set-Cookie header as the others are attributes.
+func maskCookies(b []byte) []byte {
+	colon := bytes.IndexByte(b, ':')
+	set := bytes.HasPrefix(foldCase(b[:colon]), []byte("set-"))
+	out := make([]byte, 0, len(b))
+	out = append(out, b[:colon+1]...)
+
+	value := b[colon+1:]
+	start := 0
+	for i, loc := range append(patternCookieSeparator.FindAllIndex(value, -1), []int{len(value), len(value)}) {
+		out = maskCookie(out, value[start:loc[0]], !set || i == 0)
+		out = append(out, value[loc[0]:loc[1]]...)
+		start = loc[1]
+	}
+	return out
+}
+
+// maskCookie appends the name=value pair to out, masking the value
+// if needed. Pairs without value are appended unchanged.
+func maskCookie(out, pair []byte, mask bool) []byte {
+	eq := bytes.IndexByte(pair, '=')
+	if eq < 0 || !mask {
+		return append(out, pair...)
+	}
+	value := pair[eq+1:]
+	trimmed := bytes.TrimRight(value, " \t")
+	out = append(out, pair[:eq+1]...)
+	if len(trimmed) > 0 {
+		out = append(out, defaultMask...)
+	}
+	return append(out, value[len(trimmed):]...)
+}
@@ -0,0 +1,34 @@
+package logger
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMaskCookiesDump(t *testing.T) {
+	dump, err := os.ReadFile(filepath.Join("testdata", "cookie_dump.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden := filepath.Join("testdata", "cookie_dump.golden")
+	got := NewMasker(MaskCookies()).Mask(dump)
+	if os.Getenv(UpdateGoldenEnv) == "true" {
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("cookie masking differs:\n%s", lineDiff(golden, string(want), string(got)))
+	}
+	for _, secret := range []string{"9f8e7d6c5b4a", "Zm9vYmFy", "0a1b2c3d4e5f", "YmFyYmF6", "=1", "=2", "=3"} {
+		if bytes.Contains(got, []byte(secret)) {
+			t.Errorf("got %s unmasked:\n%s", secret, got)
+		}
+	}
+}
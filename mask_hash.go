@@ -0,0 +1,102 @@
+package logger
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// HashSecretEnv the environment variable holding the secret used
+// to hash the values when none is configured. It is read once,
+// a random secret is used if it is not set.
+const HashSecretEnv = "LOGGER_HASH_SECRET"
+
+// hashLength the number of hex characters of the hash tokens.
+const hashLength = 12
+
+// MaskMode defines how a MaskRule replaces the secrets.
+type MaskMode int
+
+const (
+	// MaskModeMask replaces the secrets with a mask.
+	MaskModeMask MaskMode = iota
+	// MaskModeHash replaces the secrets with "h:" followed by the first
+	// 12 hex characters of their HMAC-SHA256, so the same secret can be
+	// correlated across entries without being logged.
+	MaskModeHash
+)
+
+// Secret is a string that is never printed.
+type Secret string
+
+// String implements fmt.Stringer.
+func (Secret) String() string {
+	return RedactedValue
+}
+
+// GoString implements fmt.GoStringer.
+func (Secret) GoString() string {
+	return RedactedValue
+}
+
+var (
+	envHashSecretOnce sync.Once
+	envHashSecret     []byte
+)
+
+// defaultHashSecret returns the secret read from HashSecretEnv,
+// a random one if not set.
+func defaultHashSecret() []byte {
+	envHashSecretOnce.Do(func() {
+		if s := os.Getenv(HashSecretEnv); s != "" {
+			envHashSecret = []byte(s)
+			return
+		}
+		envHashSecret = make([]byte, sha256.Size)
+		_, _ = rand.Read(envHashSecret)
+	})
+	return envHashSecret
+}
+
+// appendHash appends the hash token of value to out.
+func appendHash(out, secret, value []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(value)
+	sum := mac.Sum(nil)
+	out = append(out, "h:"...)
+	return append(out, hex.EncodeToString(sum[:hashLength/2])...)
+}
+
+// hashValue returns the hash token of a field value.
+func hashValue(secret []byte, v interface{}) string {
+	var b []byte
+	switch t := v.(type) {
+	case string:
+		b = []byte(t)
+	case []byte:
+		b = t
+	default:
+		b = []byte(fmt.Sprint(v))
+	}
+	return string(appendHash(nil, secret, b))
+}
+
+// WithHashSecret returns a copy of the masker hashing
+// the secrets of the MaskModeHash rules with the given secret.
+func (m *Masker) WithHashSecret(secret Secret) *Masker {
+	cp := m.clone()
+	cp.hashSecret = []byte(secret)
+	return cp
+}
+
+// secret returns the secret used to hash the values.
+func (m *Masker) secret() []byte {
+	if len(m.hashSecret) > 0 {
+		return m.hashSecret
+	}
+	return defaultHashSecret()
+}
@@ -0,0 +1,65 @@
+package logger
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var hashToken = regexp.MustCompile(`^h:[0-9a-f]{12}$`)
+
+func TestHashKeys(t *testing.T) {
+	rec := NewRecorder()
+	newLogger := func(secret Secret) Logger {
+		return NewWithWriter(Config{HashKeys: map[string]bool{"email": true}, HashSecret: secret}, rec)
+	}
+	lg := newLogger("s3cr3t")
+	lg.Infow("login", "email", "bob@example.com")
+	lg.With("email", "bob@example.com").Info("logout")
+	lg.Infow("login", "email", "alice@example.com")
+	newLogger("other").Infow("login", "email", "bob@example.com")
+
+	var tokens []string
+	for _, e := range rec.Entries() {
+		v, _ := e.Field("email")
+		token := fmt.Sprint(v)
+		if !hashToken.MatchString(token) {
+			t.Fatalf("got %q, want h: and 12 hex characters", token)
+		}
+		tokens = append(tokens, token)
+	}
+	if tokens[0] != tokens[1] {
+		t.Errorf("the same value gave %s and %s", tokens[0], tokens[1])
+	}
+	if tokens[0] == tokens[2] {
+		t.Errorf("different values gave the same token %s", tokens[0])
+	}
+	if tokens[0] == tokens[3] {
+		t.Errorf("different secrets gave the same token %s", tokens[0])
+	}
+	if dump := string(rec.Dump()); strings.Contains(dump, "s3cr3t") || strings.Contains(dump, "bob@") {
+		t.Errorf("the secret or the value was logged:\n%s", dump)
+	}
+}
+
+func TestMaskModeHash(t *testing.T) {
+	rule := MaskEmails()
+	rule.Mode = MaskModeHash
+	m := NewMasker(rule).WithHashSecret("s3cr3t")
+
+	got := m.MaskString("user bob@example.com")
+	if got != m.MaskString("user bob@example.com") {
+		t.Errorf("the masking is not deterministic")
+	}
+	token := strings.TrimPrefix(got, "user ")
+	if !regexp.MustCompile(`^h:[0-9a-f]{12}@example\.com$`).MatchString(token) {
+		t.Errorf("got %q, want the hashed local part", got)
+	}
+	if other := NewMasker(rule).WithHashSecret("other").MaskString("user bob@example.com"); other == got {
+		t.Errorf("different secrets gave the same token %s", got)
+	}
+	if s := fmt.Sprintf("%v %+v %#v", Secret("s3cr3t"), Secret("s3cr3t"), Secret("s3cr3t")); strings.Contains(s, "s3cr3t") {
+		t.Errorf("the secret was printed: %s", s)
+	}
+}
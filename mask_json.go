@@ -0,0 +1,166 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+)
+
+// jsonMaskValue replaces the values masked by MaskJSON.
+const jsonMaskValue = "***"
+
+// MaskJSON masks the values of the given keys in a JSON document,
+// re-serializing it compactly with the same key order.
+// Keys are case insensitive and can be dotted paths from the root
+// object, e.g. "credentials.password", a key without dots matches
+// at any depth. The '*' wildcard matches any sequence of characters
+// within a path segment.
+// If b is not valid JSON it is masked using the DefaultMasker.
+func MaskJSON(b []byte, keys ...string) []byte {
+	patterns := make([][]string, len(keys))
+	for i, k := range keys {
+		patterns[i] = strings.Split(strings.ToLower(k), ".")
+	}
+
+	m := jsonMasker{
+		dec:      json.NewDecoder(bytes.NewReader(b)),
+		patterns: patterns,
+	}
+	m.dec.UseNumber()
+	if err := m.value(); err != nil {
+		return SecretMask(b)
+	}
+	if _, err := m.dec.Token(); err != io.EOF {
+		return SecretMask(b)
+	}
+	return m.out.Bytes()
+}
+
+type jsonMasker struct {
+	dec      *json.Decoder
+	out      bytes.Buffer
+	patterns [][]string
+	path     []string
+}
+
+var errInvalidJSON = errors.New("invalid json")
+
+func (m *jsonMasker) value() error {
+	tok, err := m.dec.Token()
+	if err != nil {
+		return err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			return m.object()
+		case '[':
+			return m.array()
+		}
+		return errInvalidJSON
+	default:
+		return m.literal(t)
+	}
+}
+
+func (m *jsonMasker) object() error {
+	m.out.WriteByte('{')
+	for i := 0; m.dec.More(); i++ {
+		tok, err := m.dec.Token()
+		if err != nil {
+			return err
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return errInvalidJSON
+		}
+		if i > 0 {
+			m.out.WriteByte(',')
+		}
+		if err := m.literal(key); err != nil {
+			return err
+		}
+		m.out.WriteByte(':')
+
+		m.path = append(m.path, strings.ToLower(key))
+		if m.masked() {
+			var raw json.RawMessage
+			if err := m.dec.Decode(&raw); err != nil {
+				return err
+			}
+			err = m.literal(jsonMaskValue)
+		} else {
+			err = m.value()
+		}
+		m.path = m.path[:len(m.path)-1]
+		if err != nil {
+			return err
+		}
+	}
+	if _, err := m.dec.Token(); err != nil {
+		return err
+	}
+	m.out.WriteByte('}')
+	return nil
+}
+
+func (m *jsonMasker) array() error {
+	m.out.WriteByte('[')
+	for i := 0; m.dec.More(); i++ {
+		if i > 0 {
+			m.out.WriteByte(',')
+		}
+		if err := m.value(); err != nil {
+			return err
+		}
+	}
+	if _, err := m.dec.Token(); err != nil {
+		return err
+	}
+	m.out.WriteByte(']')
+	return nil
+}
+
+func (m *jsonMasker) literal(v interface{}) error {
+	if v == nil {
+		m.out.WriteString("null")
+		return nil
+	}
+	enc := json.NewEncoder(&m.out)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	// remove the new line added by the encoder
+	m.out.Truncate(m.out.Len() - 1)
+	return nil
+}
+
+// masked returns if the value at the current path must be masked.
+func (m *jsonMasker) masked() bool {
+	for _, p := range m.patterns {
+		if len(p) == 1 {
+			if globMatch(p[0], m.path[len(m.path)-1]) {
+				return true
+			}
+			continue
+		}
+		if len(p) != len(m.path) {
+			continue
+		}
+		matched := true
+		for i := range p {
+			if !globMatch(p[i], m.path[i]) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
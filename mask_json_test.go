@@ -0,0 +1,30 @@
+package logger
+
+import "testing"
+
+func TestMaskJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		keys []string
+		want string
+	}{
+		{"nested arrays of objects", `{"users":[{"name":"a","Password":"x1"},{"name":"b","password":"x2"}]}`, []string{"password"},
+			`{"users":[{"name":"a","Password":"***"},{"name":"b","password":"***"}]}`},
+		{"dotted path", "{\n  \"credentials\": {\"password\": \"p\\\"w\"},\n  \"password\": \"top\"\n}", []string{"credentials.password"},
+			`{"credentials":{"password":"***"},"password":"top"}`},
+		{"wildcard", `{"db":{"api_key":"k1","api_secret":"s1","host":"h"}}`, []string{"db.api_*"},
+			`{"db":{"api_key":"***","api_secret":"***","host":"h"}}`},
+		{"arrays of arrays", `{"a":[[{"token":"t"}]],"n":1.50,"b":true,"z":null}`, []string{"token"},
+			`{"a":[[{"token":"***"}]],"n":1.50,"b":true,"z":null}`},
+		{"invalid json", `{"password":"hunter2secret", broken`, []string{"password"},
+			`{"password":"hu***t", broken`},
+		{"not json", `authorization: Bearer abcdefghijkl`, []string{"password"},
+			`authorization: Bearer abc*****jkl`},
+	}
+	for _, tt := range tests {
+		if got := string(MaskJSON([]byte(tt.in), tt.keys...)); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
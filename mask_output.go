@@ -0,0 +1,29 @@
+package logger
+
+import (
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+var maskBufferPool = buffer.NewPool()
+
+// maskingEncoder masks every encoded entry before it is written.
+type maskingEncoder struct {
+	zapcore.Encoder
+	masker *Masker
+}
+
+func (e maskingEncoder) Clone() zapcore.Encoder {
+	return maskingEncoder{Encoder: e.Encoder.Clone(), masker: e.masker}
+}
+
+func (e maskingEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	buf, err := e.Encoder.EncodeEntry(ent, fields)
+	if err != nil {
+		return nil, err
+	}
+	out := maskBufferPool.Get()
+	_, _ = out.Write(e.masker.Mask(buf.Bytes()))
+	buf.Free()
+	return out, nil
+}
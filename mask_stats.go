@@ -0,0 +1,65 @@
+package logger
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// onRedactInterval the minimum interval between two
+// OnRedact callback calls for the same rule.
+const onRedactInterval = time.Second
+
+// maskStats counts the replacements of every masker rule.
+type maskStats struct {
+	counts []uint64
+	called []int64
+}
+
+func newMaskStats(n int) *maskStats {
+	return &maskStats{
+		counts: make([]uint64, n),
+		called: make([]int64, n),
+	}
+}
+
+// Stats returns the number of replacements done by every rule, by name.
+// Copies of the masker, e.g. WithKeep, share the stats of the original.
+func (m *Masker) Stats() map[string]uint64 {
+	stats := make(map[string]uint64, len(m.rules))
+	for i, r := range m.rules {
+		stats[r.Name] += atomic.LoadUint64(&m.stats.counts[i])
+	}
+	return stats
+}
+
+// WithOnRedact returns a copy of the masker calling fn with the rule
+// name when a secret is replaced. fn is called at most once per second
+// for every rule.
+func (m *Masker) WithOnRedact(fn func(rule string)) *Masker {
+	cp := m.clone()
+	cp.onRedact = fn
+	return cp
+}
+
+// redacted records a replacement of the i-th rule.
+func (m *Masker) redacted(i int) {
+	atomic.AddUint64(&m.stats.counts[i], 1)
+	if m.onRedact == nil {
+		return
+	}
+	now := time.Now().UnixNano()
+	last := atomic.LoadInt64(&m.stats.called[i])
+	if now-last < int64(onRedactInterval) || !atomic.CompareAndSwapInt64(&m.stats.called[i], last, now) {
+		return
+	}
+	m.onRedact(m.rules[i].Name)
+}
+
+// detached returns a copy of the masker with its own stats
+// and without OnRedact callback.
+func (m *Masker) detached() *Masker {
+	cp := m.clone()
+	cp.stats = newMaskStats(len(m.rules))
+	cp.onRedact = nil
+	return cp
+}
@@ -0,0 +1,33 @@
+package logger
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMaskerStats(t *testing.T) {
+	var redacted []string
+	m := NewMasker(DefaultMasker.Rules()...).WithOnRedact(func(rule string) {
+		redacted = append(redacted, rule)
+	})
+	m.MaskString("Bearer abcdefghijkl then bearer mnopqrstuvwx")
+	m.WithKeep(0, 0).MaskString("card 4111 1111 1111 1111, not 4111 1111 1111 1112")
+	m.MaskString("ssn 123-45-6789 and 987-65-4321")
+	m.MaskString("no secrets here")
+
+	want := map[string]uint64{"bearer": 2, "card_number": 1, "ssn": 2}
+	for rule, n := range m.Stats() {
+		if n != want[rule] {
+			t.Errorf("%s: got %d replacements, want %d", rule, n, want[rule])
+		}
+	}
+	if fmt.Sprint(redacted) != "[bearer card_number ssn]" {
+		t.Errorf("got OnRedact calls %v, want one per rule and second", redacted)
+	}
+
+	for rule, n := range NewMasker(m.Rules()...).Stats() {
+		if n != 0 {
+			t.Errorf("%s: got %d replacements in a new masker, want its own stats", rule, n)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package logger
+
+import (
+	"regexp"
+	"strings"
+)
+
+// DefaultURLParams the query parameters masked by MaskURLParams
+// when none is given.
+var DefaultURLParams = []string{"token", "access_token", "key", "signature", "password"}
+
+var urlMasker = NewMasker(MaskURLParams())
+
+// MaskURLParams returns a rule masking the values of the given query
+// parameters in the URLs, case insensitive, leaving the rest of the URL
+// untouched. Both '&' and ';' separators are supported, as well as the
+// parameters of the fragment, e.g. an OAuth access_token, and every
+// occurrence of a repeated parameter is masked. The values are masked
+// as they are, URL-encoded or not.
+//
+// The rule does not parse the URLs: a parameter preceded by '?', '&',
+// ';' or '#' is masked anywhere in the text, e.g. "a&token=x" in a log
+// message or a form body, but not "token=x" or " token=x".
+func MaskURLParams(params ...string) MaskRule {
+	if len(params) == 0 {
+		params = DefaultURLParams
+	}
+	quoted := make([]string, len(params))
+	literals := make([]string, len(params))
+	for i, p := range params {
+		quoted[i] = regexp.QuoteMeta(p)
+		literals[i] = p + "="
+	}
+	return MaskRule{
+		Name:     "url_params",
+		Pattern:  regexp.MustCompile(`(?i)[?&;#](?:` + strings.Join(quoted, "|") + `)=(?P<secret>[^&;#\s"'<>]+)`),
+		Mask:     "***",
+		Literals: literals,
+	}
+}
+
+// SanitizeURL masks the values of the DefaultURLParams query
+// parameters of u, so it can be safely added as a field.
+// Usage: lg.With("url", logger.SanitizeURL(req.URL.String()))
+func SanitizeURL(u string) string {
+	return urlMasker.MaskString(u)
+}
@@ -0,0 +1,35 @@
+package logger
+
+import "testing"
+
+func TestSanitizeURL(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"single", "https://api.example.com/v1?token=abc123", "https://api.example.com/v1?token=***"},
+		{"multiple", "/cb?user=bob&access_token=a1b2&signature=c3d4&page=2", "/cb?user=bob&access_token=***&signature=***&page=2"},
+		{"repeated", "/q?key=k1&key=k2;key=k3", "/q?key=***&key=***;key=***"},
+		{"case insensitive", "/q?Password=hunter2", "/q?Password=***"},
+		{"url encoded", "/q?token=a%2Bb%3D", "/q?token=***"},
+		{"fragment", "/cb#access_token=a1b2&token_type=bearer", "/cb#access_token=***&token_type=bearer"},
+		{"query and fragment", "/cb?token=a1b2#key=c3d4", "/cb?token=***#key=***"},
+		{"quoted", `"url":"/q?token=a1b2"`, `"url":"/q?token=***"`},
+		{"empty value", "/q?token=&page=2", "/q?token=&page=2"},
+		{"other params", "/q?tokens=1&monkey=2", "/q?tokens=1&monkey=2"},
+		{"free text separator", "retry a&token=a1b2 later", "retry a&token=*** later"},
+		{"free text", "token=a1b2 and the key=c3d4", "token=a1b2 and the key=c3d4"},
+	}
+	for _, tt := range tests {
+		if got := SanitizeURL(tt.in); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMaskURLParams(t *testing.T) {
+	m := NewMasker(MaskURLParams("sig", "session"))
+	in := "/q?sig=abc&token=def&SESSION=ghi"
+	if got, want := m.MaskString(in), "/q?sig=***&token=def&SESSION=***"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
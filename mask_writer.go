@@ -0,0 +1,66 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// maxMaskingBuffer is the maximum number of bytes a masking writer
+// buffers waiting for a new line before masking and flushing them.
+const maxMaskingBuffer = 64 * 1024
+
+// maskingWriter masks the data written line by line.
+type maskingWriter struct {
+	mu     sync.Mutex
+	w      io.Writer
+	masker *Masker
+	buf    bytes.Buffer
+}
+
+// NewMaskingWriter returns a writer masking the data flowing through it
+// to w. Data is buffered until a new line so secrets split across Write
+// calls are masked too, Close flushes the pending data without closing w.
+// DefaultMasker is used when m is nil. See Logger.Writer to log the
+// masked data.
+func NewMaskingWriter(w io.Writer, m *Masker) io.WriteCloser {
+	if m == nil {
+		m = DefaultMasker
+	}
+	return &maskingWriter{w: w, masker: m}
+}
+
+func (mw *maskingWriter) Write(p []byte) (int, error) {
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+
+	mw.buf.Write(p)
+	data := mw.buf.Bytes()
+	end := bytes.LastIndexByte(data, '\n') + 1
+	if end == 0 && len(data) < maxMaskingBuffer {
+		return len(p), nil
+	}
+	if end == 0 {
+		end = len(data)
+	}
+	if err := mw.flush(end); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close flushes the buffered data.
+func (mw *maskingWriter) Close() error {
+	mw.mu.Lock()
+	defer mw.mu.Unlock()
+	return mw.flush(mw.buf.Len())
+}
+
+// flush masks and writes the first n buffered bytes.
+func (mw *maskingWriter) flush(n int) error {
+	if n == 0 {
+		return nil
+	}
+	_, err := mw.w.Write(mw.masker.Mask(mw.buf.Next(n)))
+	return err
+}
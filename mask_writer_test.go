@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestMaskingWriterSplitSecret(t *testing.T) {
+	var out bytes.Buffer
+	w := NewMaskingWriter(&out, nil)
+	for _, chunk := range []string{"GET / HTTP/1.1\nAuthorization: Bearer eyJhbGciOi", "JIUzI1NiJ9secret\nAccept: */*"} {
+		if n, err := w.Write([]byte(chunk)); n != len(chunk) || err != nil {
+			t.Fatalf("got %d, %v", n, err)
+		}
+	}
+	if strings.Contains(out.String(), "Accept") {
+		t.Errorf("got %q, want the last line buffered", out.String())
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+	if strings.Contains(got, "JIUzI1NiJ9secret") || !strings.HasPrefix(got, "GET / HTTP/1.1\nAuthorization: ") || !strings.HasSuffix(got, "\nAccept: */*") {
+		t.Errorf("got %q, want the split secret masked", got)
+	}
+}
+
+func TestLoggerWriter(t *testing.T) {
+	rec := NewRecorder()
+	lg := NewWithWriter(Config{}, rec)
+	lw := lg.Writer(WarningLevel)
+	w := NewMaskingWriter(lw, nil)
+	for _, chunk := range []string{"starting\n\nAuthorization: Bearer eyJhbGciOi", "JIUzI1NiJ9secret\r\n", "exit 1"} {
+		w.Write([]byte(chunk))
+	}
+	if n := len(rec.Entries()); n != 2 {
+		t.Errorf("got %d entries before Close, want the last line buffered", n)
+	}
+	w.Close()
+	lw.Close()
+
+	entries := rec.Entries()
+	if len(entries) != 3 {
+		t.Fatalf("got entries:\n%s", rec.Dump())
+	}
+	for i, want := range []string{"starting", "Authorization: ", "exit 1"} {
+		e := entries[i]
+		if e.Level != WarningLevel || !strings.HasPrefix(e.Message(), want) || strings.ContainsAny(e.Message(), "\r\n") {
+			t.Errorf("entry %d: got %s %q, want %q", i, e.Level, e.Message(), want)
+		}
+	}
+	if strings.Contains(entries[1].Message(), "secret") {
+		t.Errorf("got %q, want the secret masked", entries[1].Message())
+	}
+}
@@ -0,0 +1,54 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Mode is the logging mode of the default writer, see Config.Mode.
+type Mode string
+
+// Available logging modes.
+const (
+	// ModeProduction logs json entries to stdout, with
+	// the stack trace of the error entries. The default.
+	ModeProduction Mode = "production"
+	// ModeDevelopment logs colored console entries to stderr,
+	// with the stack trace of the warning entries, and makes
+	// the DPanicLevel entries panic.
+	ModeDevelopment Mode = "development"
+)
+
+// parseMode returns the mode of its string representation, case
+// insensitive and ignoring the surrounding spaces: "dev" or
+// "development", "prod" or "production", and empty for the
+// default one. An error is returned if the mode is unknown.
+func parseMode(mode string) (Mode, error) {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "":
+		return "", nil
+	case "prod", "production":
+		return ModeProduction, nil
+	case "dev", "development":
+		return ModeDevelopment, nil
+	default:
+		return "", fmt.Errorf("unknown mode %q, use dev or prod", mode)
+	}
+}
+
+// mode returns the logging mode of the config: its Mode when
+// set, or the one of its Log, ModeProduction by default.
+func (c Config) mode() Mode {
+	if c.Mode != "" {
+		return c.Mode
+	}
+	if m, err := parseMode(c.Log); err == nil && m != "" {
+		return m
+	}
+	return ModeProduction
+}
+
+// development returns if the config selects the ModeDevelopment.
+func (c Config) development() bool {
+	return c.mode() == ModeDevelopment
+}
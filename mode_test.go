@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigMode(t *testing.T) {
+	tests := []struct {
+		cfg  Config
+		want Mode
+	}{
+		{Config{}, ModeProduction},
+		{Config{Mode: ModeDevelopment}, ModeDevelopment},
+		{Config{Mode: ModeProduction, Log: "Dev"}, ModeProduction},
+		{Config{Mode: ModeDevelopment, Log: "prod"}, ModeDevelopment},
+		{Config{Log: "Dev"}, ModeDevelopment},
+		{Config{Log: "dev"}, ModeDevelopment},
+		{Config{Log: " DEV "}, ModeDevelopment},
+		{Config{Log: "Development"}, ModeDevelopment},
+		{Config{Log: "prod"}, ModeProduction},
+		{Config{Log: "PRODUCTION"}, ModeProduction},
+	}
+	for _, tt := range tests {
+		if got := tt.cfg.mode(); got != tt.want {
+			t.Errorf("Mode %q, Log %q: got %s, want %s", tt.cfg.Mode, tt.cfg.Log, got, tt.want)
+		}
+		if err := tt.cfg.Validate(); err != nil {
+			t.Errorf("Mode %q, Log %q: got error %v", tt.cfg.Mode, tt.cfg.Log, err)
+		}
+	}
+}
+
+func TestInvalidMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	tests := []struct {
+		cfg     Config
+		wantErr string
+	}{
+		{Config{Log: "staging"}, `invalid logger config: log: unknown mode "staging", use dev or prod`},
+		{Config{Log: "devel"}, `invalid logger config: log: unknown mode "devel", use dev or prod`},
+		{Config{Mode: "dev"}, `invalid logger config: unknown mode "dev", use ModeProduction or ModeDevelopment`},
+		{Config{Mode: ModeDevelopment, Log: "staging"}, `invalid logger config: log: unknown mode "staging", use dev or prod`},
+	}
+	for _, tt := range tests {
+		tt.cfg.OutputPaths = []string{path}
+		_, err := New(tt.cfg)
+		if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+			t.Errorf("Mode %q, Log %q: got error %v, want %q", tt.cfg.Mode, tt.cfg.Log, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+// Package otellogger provides an OpenTelemetry logs API backend
+// writing through the github.com/Aibier/go-logger Logger.
+package otellogger
+
+import (
+	"context"
+
+	logger "github.com/Aibier/go-logger"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/embedded"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Field keys of the trace correlation fields.
+const (
+	TraceIDKey = "trace_id"
+	SpanIDKey  = "span_id"
+)
+
+// EventNameKey is the field key of the record event name.
+const EventNameKey = "event"
+
+// LoggerProvider is a log.LoggerProvider writing through a Logger.
+type LoggerProvider struct {
+	embedded.LoggerProvider
+	l logger.Logger
+}
+
+var _ log.LoggerProvider = LoggerProvider{}
+
+// NewOTelLoggerProvider returns a log.LoggerProvider writing the
+// records emitted through the OTel logs API to l:
+//
+//	global.SetLoggerProvider(otellogger.NewOTelLoggerProvider(lg))
+//
+// The records have a "logger" field with the name of the OTel logger,
+// their attributes as fields, the nested maps flattened with dotted
+// keys, and the trace_id and span_id fields of the span of the emit
+// context. The records are timestamped by the Logger, and the caller
+// of Emit is reported.
+func NewOTelLoggerProvider(l logger.Logger) log.LoggerProvider {
+	return LoggerProvider{l: l.WithCallerSkip(1)}
+}
+
+// Logger implements log.LoggerProvider.
+func (p LoggerProvider) Logger(name string, _ ...log.LoggerOption) log.Logger {
+	l := p.l
+	if name != "" {
+		l = l.Named(name)
+	}
+	return otelLogger{l: l}
+}
+
+type otelLogger struct {
+	embedded.Logger
+	l logger.Logger
+}
+
+// Emit implements log.Logger.
+func (o otelLogger) Emit(ctx context.Context, record log.Record) {
+	fields := make([]interface{}, 0, 2*record.AttributesLen()+6)
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		fields = append(fields, TraceIDKey, sc.TraceID().String(), SpanIDKey, sc.SpanID().String())
+	}
+	if name := record.EventName(); name != "" {
+		fields = append(fields, EventNameKey, name)
+	}
+	record.WalkAttributes(func(kv attribute.KeyValue) bool {
+		fields = appendAttribute(fields, string(kv.Key), kv.Value)
+		return true
+	})
+
+	l := o.l
+	if len(fields) > 0 {
+		l = l.With(fields...)
+	}
+	if err := record.Err(); err != nil {
+		l = l.WithError(err)
+	}
+	l.Log(Level(record.Severity()), record.Body().String())
+}
+
+// Enabled implements log.Logger, using Logger.Enabled. An
+// undefined severity is enabled if any level is.
+func (o otelLogger) Enabled(_ context.Context, param log.EnabledParameters) bool {
+	if param.Severity == log.SeverityUndefined {
+		return o.l.Enabled(logger.ErrorLevel)
+	}
+	return o.l.Enabled(Level(param.Severity))
+}
+
+// Level returns the Logger level of an OTel severity. The undefined
+// severity is logged as info, and the fatal ones as error as the
+// bridge never exits the process.
+func Level(s log.Severity) logger.Level {
+	switch {
+	case s == log.SeverityUndefined:
+		return logger.InfoLevel
+	case s < log.SeverityInfo1:
+		return logger.DebugLevel
+	case s < log.SeverityWarn1:
+		return logger.InfoLevel
+	case s < log.SeverityError1:
+		return logger.WarningLevel
+	default:
+		return logger.ErrorLevel
+	}
+}
+
+// appendAttribute appends the key/value pairs of an attribute,
+// flattening the maps with dotted keys.
+func appendAttribute(fields []interface{}, key string, v attribute.Value) []interface{} {
+	if v.Type() != attribute.MAP {
+		return append(fields, key, value(v))
+	}
+	for _, kv := range v.AsMap() {
+		fields = appendAttribute(fields, key+"."+string(kv.Key), kv.Value)
+	}
+	return fields
+}
+
+// value returns the Go value of an attribute value.
+func value(v attribute.Value) interface{} {
+	switch v.Type() {
+	case attribute.SLICE:
+		vs := v.AsSlice()
+		out := make([]interface{}, len(vs))
+		for i, e := range vs {
+			out[i] = value(e)
+		}
+		return out
+	case attribute.MAP:
+		kvs := v.AsMap()
+		out := make(map[string]interface{}, len(kvs))
+		for _, kv := range kvs {
+			out[string(kv.Key)] = value(kv.Value)
+		}
+		return out
+	}
+	return v.AsInterface()
+}
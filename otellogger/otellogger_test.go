@@ -0,0 +1,142 @@
+package otellogger
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	logger "github.com/Aibier/go-logger"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// newRecord returns a record with the given severity and body.
+func newRecord(s log.Severity, body string, attrs ...attribute.KeyValue) log.Record {
+	var r log.Record
+	r.SetSeverity(s)
+	r.SetBody(attribute.StringValue(body))
+	r.AddAttributes(attrs...)
+	return r
+}
+
+func TestEmit(t *testing.T) {
+	rec := logger.NewRecorder()
+	ol := NewOTelLoggerProvider(logger.NewWithWriter(logger.Config{Level: logger.DebugLevel}, rec)).Logger("checkout")
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1, 2, 3},
+		SpanID:  trace.SpanID{4, 5, 6},
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+	failure := errors.New("card declined")
+	r := newRecord(log.SeverityError, "payment failed",
+		attribute.String("user", "ann"),
+		attribute.Int("amount", 42),
+		attribute.Map("card", attribute.String("brand", "visa"), attribute.Map("exp", attribute.Int("year", 2030))),
+		attribute.Slice("items", attribute.StringValue("a"), attribute.Int64Value(2)),
+	)
+	r.SetEventName("payment.failed")
+	r.SetErr(failure)
+	ol.Emit(ctx, r)
+
+	e, ok := rec.LastEntry()
+	if !ok || e.Level != logger.ErrorLevel || e.Message() != "payment failed" {
+		t.Fatalf("got entry %v", e)
+	}
+	for k, want := range map[string]interface{}{
+		logger.NameFieldKey: "checkout",
+		TraceIDKey:          sc.TraceID().String(),
+		SpanIDKey:           sc.SpanID().String(),
+		EventNameKey:        "payment.failed",
+		"user":              "ann",
+		"amount":            int64(42),
+		"card.brand":        "visa",
+		"card.exp.year":     int64(2030),
+		"error":             failure.Error(),
+	} {
+		if v, _ := e.Field(k); v != want {
+			t.Errorf("%s: got %#v, want %#v", k, v, want)
+		}
+	}
+	if v, _ := e.Field("items"); len(v.([]interface{})) != 2 {
+		t.Errorf("got items %v", v)
+	}
+
+	rec.Reset()
+	NewOTelLoggerProvider(logger.NewWithWriter(logger.Config{}, rec)).Logger("").Emit(context.Background(), newRecord(log.SeverityInfo, "no span"))
+	e, _ = rec.LastEntry()
+	for _, k := range []string{logger.NameFieldKey, TraceIDKey, SpanIDKey, EventNameKey} {
+		if v, ok := e.Field(k); ok {
+			t.Errorf("%s: got %v, want no field", k, v)
+		}
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	lg := logger.NewWithWriter(logger.Config{Level: logger.WarningLevel}, logger.NewRecorder())
+	ol := NewOTelLoggerProvider(lg).Logger("test")
+	for s, want := range map[log.Severity]bool{
+		log.SeverityUndefined: true,
+		log.SeverityDebug:     false,
+		log.SeverityInfo4:     false,
+		log.SeverityWarn:      true,
+		log.SeverityFatal:     true,
+	} {
+		if got := ol.Enabled(context.Background(), log.EnabledParameters{Severity: s}); got != want {
+			t.Errorf("%v: got enabled %v, want %v", s, got, want)
+		}
+	}
+}
+
+func TestLevel(t *testing.T) {
+	for s, want := range map[log.Severity]logger.Level{
+		log.SeverityUndefined: logger.InfoLevel,
+		log.SeverityTrace:     logger.DebugLevel,
+		log.SeverityDebug4:    logger.DebugLevel,
+		log.SeverityInfo:      logger.InfoLevel,
+		log.SeverityWarn2:     logger.WarningLevel,
+		log.SeverityError:     logger.ErrorLevel,
+		log.SeverityFatal4:    logger.ErrorLevel,
+	} {
+		if got := Level(s); got != want {
+			t.Errorf("%v: got %v, want %v", s, got, want)
+		}
+	}
+}
+
+func TestEmitCaller(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.log")
+	lg, err := logger.New(logger.Config{OutputPaths: []string{path}, DisableInitialFields: true, DisableStacktrace: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ol := NewOTelLoggerProvider(lg).Logger("test")
+	ol.Emit(context.Background(), newRecord(log.SeverityInfo, "info"))
+	ol.Emit(context.Background(), newRecord(log.SeverityError, "error", attribute.String("k", "v")))
+	lg.Sync()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var n int
+	for sc := bufio.NewScanner(f); sc.Scan(); n++ {
+		var e struct{ Msg, Caller string }
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(e.Caller, "/otellogger_test.go:") {
+			t.Errorf("%s: got caller %q, want the Emit caller", e.Msg, e.Caller)
+		}
+	}
+	if n != 2 {
+		t.Errorf("got %d entries, want 2", n)
+	}
+}
@@ -0,0 +1,162 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Output is an output path with its minimum level, see Config.Outputs.
+type Output struct {
+	// Path the output path, see Config.OutputPaths.
+	Path string
+
+	// MinLevel the minimum level of the entries written to the
+	// output, the entries must be enabled by the logger level too.
+	MinLevel Level
+}
+
+// outputPaths returns the paths of the outputs.
+func outputPaths(outputs []Output) []string {
+	paths := make([]string, len(outputs))
+	for i, o := range outputs {
+		paths[i] = o.Path
+	}
+	return paths
+}
+
+// outputPaths returns the paths of the Outputs, the given
+// default ones when there are none.
+func (c Config) outputPaths(defaults []string) []string {
+	if len(c.Outputs) == 0 {
+		return defaults
+	}
+	return outputPaths(c.Outputs)
+}
+
+// outputLevel enables the entries enabled by the logger
+// level and at least of the output minimum level.
+type outputLevel struct {
+	logger zapcore.LevelEnabler
+	min    zapcore.Level
+}
+
+func (o outputLevel) Enabled(l zapcore.Level) bool {
+	return l >= o.min && o.logger.Enabled(l)
+}
+
+// Level returns the minimum enabled level, as zapcore.LevelOf expects.
+func (o outputLevel) Level() zapcore.Level {
+	if l := zapcore.LevelOf(o.logger); l > o.min {
+		return l
+	}
+	return o.min
+}
+
+// outputCores returns a core per output, writing the entries enabled
+// by its level, or a single one writing to the zap config output paths
+// when there are none, and a function closing their sinks.
+func outputCores(zcfg zap.Config, outputs []Output, enc zapcore.Encoder) ([]zapcore.Core, func(), error) {
+	var cores []zapcore.Core
+	var closers []func()
+	closeAll := func() {
+		for _, c := range closers {
+			c()
+		}
+	}
+	addCore := func(level zapcore.LevelEnabler, paths ...string) error {
+		sink, closeOut, err := zap.Open(paths...)
+		if err != nil {
+			return err
+		}
+		closers = append(closers, closeOut)
+		cores = append(cores, zapcore.NewCore(enc.Clone(), sink, level))
+		return nil
+	}
+	if len(outputs) == 0 {
+		if err := addCore(zcfg.Level, zcfg.OutputPaths...); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, o := range outputs {
+		if err := addCore(outputLevel{logger: zcfg.Level, min: zapLevel(o.MinLevel)}, o.Path); err != nil {
+			closeAll()
+			return nil, nil, err
+		}
+	}
+	return cores, closeAll, nil
+}
+
+// validateOutputPaths checks the file output paths can be opened,
+// returning an error listing all the invalid ones. The other
+// schemes are left to zap, which may have custom sinks registered.
+func validateOutputPaths(paths []string) error {
+	var err error
+	for i, p := range paths {
+		if perr := validateOutputPath(p); perr != nil {
+			err = multierr.Append(err, fmt.Errorf("output path %d %q: %w", i, p, perr))
+		}
+	}
+	return err
+}
+
+func validateOutputPath(p string) error {
+	switch strings.TrimSpace(p) {
+	case "":
+		return errors.New("empty path")
+	case "stdout", "stderr":
+		return nil
+	}
+	if filepath.IsAbs(p) {
+		return validateOutputFile(p)
+	}
+	u, err := url.Parse(p)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+	switch u.Scheme {
+	case "":
+		return validateOutputFile(p)
+	case "file":
+		if u.Path == "" {
+			return errors.New("missing file path")
+		}
+		return validateOutputFile(u.Path)
+	}
+	return nil
+}
+
+// validateOutputFile checks the file can be opened for writing, as
+// zap would do, without creating it: when it doesn't exist a probe file
+// is created in its directory, and removed.
+func validateOutputFile(path string) error {
+	dir := filepath.Dir(path)
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("directory %s: %w", dir, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err == nil {
+		return f.Close()
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	probe, err := os.CreateTemp(dir, ".logger-probe-*")
+	if err != nil {
+		return fmt.Errorf("can't create files in directory %s: %w", dir, err)
+	}
+	probe.Close()
+	return os.Remove(probe.Name())
+}
@@ -0,0 +1,162 @@
+package logger
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// readOnlyDir returns a directory where files can't be created.
+func readOnlyDir(t *testing.T) string {
+	if os.Geteuid() == 0 {
+		if runtime.GOOS != "linux" {
+			t.Skip("no read-only directory for root")
+		}
+		return "/proc"
+	}
+	dir := t.TempDir()
+	if err := os.Chmod(dir, 0o500); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chmod(dir, 0o700) })
+	return dir
+}
+
+func TestInvalidOutputPaths(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing", "app.log")
+	readOnly := filepath.Join(readOnlyDir(t), "app.log")
+
+	_, err := New(Config{OutputPaths: []string{"stdout", missing, readOnly}})
+	if err == nil {
+		t.Fatal("got no error")
+	}
+	for _, want := range []string{`output path 1 "` + missing + `"`, `output path 2 "` + readOnly + `"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("got error %q, want it to name %s", err, want)
+		}
+	}
+
+	_, err = New(Config{OutputPaths: []string{"bogus://host/app.log"}})
+	if err == nil || !strings.Contains(err.Error(), "bogus://host/app.log") {
+		t.Errorf("got error %v, want it to name the path", err)
+	}
+}
+
+func TestValidateOutputPathsCreatesNoFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := (Config{OutputPaths: []string{filepath.Join(dir, "app.log")}}).Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) > 0 {
+		t.Errorf("validating created %s", entries[0].Name())
+	}
+
+	existing := filepath.Join(dir, "existing.log")
+	if err := os.WriteFile(existing, []byte("entry\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := (Config{OutputPaths: []string{existing}}).Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := os.ReadFile(existing); string(b) != "entry\n" {
+		t.Errorf("validating modified the existing file: %q", b)
+	}
+}
+
+func TestEmptyOutputPaths(t *testing.T) {
+	for _, paths := range [][]string{nil, {}} {
+		var warnings []string
+		cfg := Config{OutputPaths: paths, DisableInitialFields: true, OnValidateWarning: func(w string) {
+			warnings = append(warnings, w)
+		}}
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("%#v: got error %v", paths, err)
+		}
+		if want := paths != nil; (len(warnings) == 1) != want || len(warnings) > 1 {
+			t.Errorf("%#v: got warnings %q", paths, warnings)
+		}
+		if out := captureStdout(t, func() {
+			lg, err := New(cfg)
+			if err != nil {
+				t.Fatalf("%#v: got error %v", paths, err)
+			}
+			lg.Info("to stdout")
+		}); !strings.Contains(out, "to stdout") {
+			t.Errorf("%#v: got stdout %q", paths, out)
+		}
+	}
+
+	var warning string
+	Config{ErrorOutputPaths: []string{}, OnValidateWarning: func(w string) { warning = w }}.Validate()
+	if !strings.Contains(warning, "empty error output paths") {
+		t.Errorf("got warning %q for empty error output paths", warning)
+	}
+
+	for _, paths := range [][]string{{""}, {"stdout", " "}} {
+		if _, err := New(Config{OutputPaths: paths}); err == nil || !strings.Contains(err.Error(), "empty path") {
+			t.Errorf("%q: got error %v, want an empty path one", paths, err)
+		}
+	}
+}
+
+// captureStdout returns what fn writes to stdout.
+func captureStdout(t *testing.T, fn func()) string {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+	fn()
+	w.Close()
+	b, _ := io.ReadAll(r)
+	return string(b)
+}
+
+func TestOutputsLevels(t *testing.T) {
+	for _, mask := range []bool{false, true} {
+		all, allSink := newMemorySink(t)
+		warnings, warningsSink := newMemorySink(t)
+		lg, err := New(Config{
+			Level:                InfoLevel,
+			Outputs:              []Output{{Path: all, MinLevel: DebugLevel}, {Path: warnings, MinLevel: WarningLevel}},
+			DisableInitialFields: true,
+			MaskOutput:           mask,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		lg.Debug("debug entry")
+		lg.Info("info entry")
+		lg.Warn("warning entry")
+		lg.Error("error entry Bearer abcdefghijkl")
+		lg.Sync()
+
+		for _, tt := range []struct {
+			name string
+			out  string
+			want []string
+		}{
+			{"all", allSink.String(), []string{"info entry", "warning entry", "error entry"}},
+			{"warnings", warningsSink.String(), []string{"warning entry", "error entry"}},
+		} {
+			lines := strings.Split(strings.TrimSpace(tt.out), "\n")
+			if len(lines) != len(tt.want) {
+				t.Fatalf("mask %v, %s: got output:\n%s", mask, tt.name, tt.out)
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(lines[i], want) {
+					t.Errorf("mask %v, %s: got line %q, want %q", mask, tt.name, lines[i], want)
+				}
+			}
+			if got := strings.Contains(tt.out, "abcdefghijkl"); got == mask {
+				t.Errorf("mask %v, %s: got output:\n%s", mask, tt.name, tt.out)
+			}
+		}
+	}
+}
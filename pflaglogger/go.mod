@@ -0,0 +1,15 @@
+module github.com/Aibier/go-logger/pflaglogger
+
+go 1.19
+
+require github.com/Aibier/go-logger v0.0.0
+
+require gopkg.in/yaml.v3 v3.0.1 // indirect
+
+require (
+	github.com/spf13/pflag v1.0.10
+	go.uber.org/multierr v1.10.0 // indirect
+	go.uber.org/zap v1.27.0 // indirect
+)
+
+replace github.com/Aibier/go-logger => ../
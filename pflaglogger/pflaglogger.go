@@ -0,0 +1,37 @@
+// Package pflaglogger registers the github.com/Aibier/go-logger flags
+// on a spf13/pflag FlagSet, e.g. the flags of a cobra command.
+package pflaglogger
+
+import (
+	"errors"
+	"flag"
+	"sync"
+
+	logger "github.com/Aibier/go-logger"
+	"github.com/spf13/pflag"
+)
+
+// configs holds the Config registered on each pflag.FlagSet.
+var configs sync.Map // map[*pflag.FlagSet]*logger.Config
+
+// RegisterFlags registers the logger flags on fs, writing into cfg
+// when parsed, see logger.RegisterFlags:
+//
+//	var cfg logger.Config
+//	pflaglogger.RegisterFlags(rootCmd.PersistentFlags(), &cfg)
+func RegisterFlags(fs *pflag.FlagSet, cfg *logger.Config) {
+	gfs := flag.NewFlagSet(fs.Name(), flag.ContinueOnError)
+	logger.RegisterFlags(gfs, cfg)
+	fs.AddGoFlagSet(gfs)
+	configs.Store(fs, cfg)
+}
+
+// NewFromFlags returns a new logger with the Config registered
+// on fs by RegisterFlags, once the flags are parsed.
+func NewFromFlags(fs *pflag.FlagSet) (logger.Logger, error) {
+	cfg, ok := configs.Load(fs)
+	if !ok {
+		return logger.Logger{}, errors.New("logger flags not registered, see RegisterFlags")
+	}
+	return logger.New(*cfg.(*logger.Config))
+}
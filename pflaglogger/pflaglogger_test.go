@@ -0,0 +1,50 @@
+package pflaglogger
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	logger "github.com/Aibier/go-logger"
+	"github.com/spf13/pflag"
+)
+
+func TestRegisterFlags(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	cfg := logger.Config{OutputPaths: []string{"stdout"}, DisableInitialFields: true}
+	RegisterFlags(fs, &cfg)
+	if err := fs.Parse([]string{"--log-level", "warning", "--log-format=console", "--log-output=stderr", "--log-dev"}); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Level != logger.WarningLevel || cfg.Encoding != "console" || cfg.Mode != logger.ModeDevelopment ||
+		len(cfg.OutputPaths) != 1 || cfg.OutputPaths[0] != "stderr" {
+		t.Errorf("got config %+v", cfg)
+	}
+	lg, err := NewFromFlags(fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lg.Enabled(logger.InfoLevel) || !lg.Enabled(logger.WarningLevel) {
+		t.Errorf("the logger does not use the parsed level")
+	}
+
+	if _, err := NewFromFlags(pflag.NewFlagSet("empty", pflag.ContinueOnError)); err == nil {
+		t.Errorf("got no error without the registered flags")
+	}
+}
+
+func TestRegisterFlagsErrors(t *testing.T) {
+	for _, argv := range [][]string{
+		{"--log-level", "verbose"},
+		{"--log-format", "logfmt"},
+		{"--log-output", " "},
+	} {
+		fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		RegisterFlags(fs, &logger.Config{})
+		if err := fs.Parse(argv); err == nil || !strings.Contains(err.Error(), strings.TrimPrefix(argv[0], "--")) {
+			t.Errorf("%q: got error %v", argv, err)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// Package pgxlogger provides a pgx v5 tracelog.Logger
+// writing through the github.com/Aibier/go-logger Logger.
+package pgxlogger
+
+import (
+	"context"
+	"sort"
+
+	logger "github.com/Aibier/go-logger"
+	"github.com/jackc/pgx/v5/tracelog"
+)
+
+// truncatedSuffix ends the SQL texts truncated by WithMaxSQLLength.
+const truncatedSuffix = "..."
+
+type config struct {
+	maxSQLLength int
+	redactArgs   bool
+}
+
+// PgxOption configures the PgxLogger.
+type PgxOption func(*config)
+
+// WithMaxSQLLength truncates the SQL texts longer than n bytes.
+func WithMaxSQLLength(n int) PgxOption {
+	return func(c *config) {
+		c.maxSQLLength = n
+	}
+}
+
+// WithRedactArgs replaces the query arguments with logger.RedactedValue.
+func WithRedactArgs() PgxOption {
+	return func(c *config) {
+		c.redactArgs = true
+	}
+}
+
+// PgxLogger is a tracelog.Logger writing through a Logger.
+type PgxLogger struct {
+	l    logger.Logger
+	conf config
+}
+
+var _ tracelog.Logger = PgxLogger{}
+
+// NewPgxLogger returns a tracelog.Logger writing to l, reporting the
+// caller of Log:
+//
+//	connConfig.Tracer = &tracelog.TraceLog{
+//		Logger:   pgxlogger.NewPgxLogger(lg, pgxlogger.WithRedactArgs()),
+//		LogLevel: tracelog.LogLevelInfo,
+//	}
+//
+// The entries have a "logger" field with the "pgx" value, the fields
+// of the Logger middlewares for the query context, e.g. the request id,
+// and the pgx data as fields, its "err" one through Logger.WithError.
+func NewPgxLogger(l logger.Logger, opts ...PgxOption) PgxLogger {
+	p := PgxLogger{l: l.Named("pgx").WithCallerSkip(1)}
+	for _, opt := range opts {
+		opt(&p.conf)
+	}
+	return p
+}
+
+// Log implements tracelog.Logger.
+func (p PgxLogger) Log(ctx context.Context, level tracelog.LogLevel, msg string, data map[string]interface{}) {
+	l := p.l.WithContext(ctx)
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	fields := make([]interface{}, 0, 2*len(keys))
+	for _, k := range keys {
+		v := data[k]
+		switch k {
+		case "err":
+			if err, ok := v.(error); ok {
+				l = l.WithError(err)
+			}
+			continue
+		case "sql":
+			if s, ok := v.(string); ok && p.conf.maxSQLLength > 0 && len(s) > p.conf.maxSQLLength {
+				v = s[:p.conf.maxSQLLength] + truncatedSuffix
+			}
+		case "args":
+			if p.conf.redactArgs {
+				v = logger.RedactedValue
+			}
+		}
+		fields = append(fields, k, v)
+	}
+	if len(fields) > 0 {
+		l = l.With(fields...)
+	}
+	l.Log(Level(level), msg)
+}
+
+// Level returns the Logger level of a pgx level, the
+// trace entries are logged as debug.
+func Level(level tracelog.LogLevel) logger.Level {
+	switch level {
+	case tracelog.LogLevelTrace, tracelog.LogLevelDebug:
+		return logger.DebugLevel
+	case tracelog.LogLevelWarn:
+		return logger.WarningLevel
+	case tracelog.LogLevelError:
+		return logger.ErrorLevel
+	default:
+		return logger.InfoLevel
+	}
+}
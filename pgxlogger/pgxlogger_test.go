@@ -0,0 +1,96 @@
+package pgxlogger
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	logger "github.com/Aibier/go-logger"
+	"github.com/jackc/pgx/v5/tracelog"
+)
+
+type requestIDKey struct{}
+
+func TestPgxLogger(t *testing.T) {
+	rec := logger.NewRecorder()
+	lg := logger.NewWithWriter(logger.Config{Level: logger.DebugLevel}, rec).WithMiddleware(func(ctx context.Context) []interface{} {
+		if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+			return []interface{}{"request_id", id}
+		}
+		return nil
+	})
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "req-1")
+	failure := errors.New("connection reset")
+
+	NewPgxLogger(lg, WithMaxSQLLength(12), WithRedactArgs()).Log(ctx, tracelog.LogLevelError, "Query", map[string]interface{}{
+		"sql":  "SELECT * FROM users WHERE id = $1",
+		"args": []interface{}{42},
+		"err":  failure,
+	})
+	e, ok := rec.LastEntry()
+	if !ok || e.Message() != "Query" || e.Level != logger.ErrorLevel {
+		t.Fatalf("got entry %v", e)
+	}
+	for k, want := range map[string]interface{}{
+		logger.NameFieldKey: "pgx",
+		"request_id":        "req-1",
+		"sql":               "SELECT * FRO" + truncatedSuffix,
+		"args":              logger.RedactedValue,
+		"error":             failure.Error(),
+	} {
+		if v, _ := e.Field(k); v != want {
+			t.Errorf("%s: got %v, want %v", k, v, want)
+		}
+	}
+	if _, ok := e.Field("err"); ok {
+		t.Errorf("the err field was logged as is")
+	}
+
+	for level, want := range map[tracelog.LogLevel]logger.Level{
+		tracelog.LogLevelTrace: logger.DebugLevel,
+		tracelog.LogLevelDebug: logger.DebugLevel,
+		tracelog.LogLevelInfo:  logger.InfoLevel,
+		tracelog.LogLevelWarn:  logger.WarningLevel,
+		tracelog.LogLevelError: logger.ErrorLevel,
+	} {
+		if got := Level(level); got != want {
+			t.Errorf("%v: got level %v, want %v", level, got, want)
+		}
+	}
+}
+
+func TestPgxLoggerCaller(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.log")
+	lg, err := logger.New(logger.Config{OutputPaths: []string{path}, DisableInitialFields: true, DisableStacktrace: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := NewPgxLogger(lg)
+	p.Log(context.Background(), tracelog.LogLevelInfo, "Query", map[string]interface{}{"sql": "SELECT 1"})
+	p.Log(context.Background(), tracelog.LogLevelError, "Exec", nil)
+	lg.Sync()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var n int
+	for sc := bufio.NewScanner(f); sc.Scan(); n++ {
+		var e struct{ Msg, Caller string }
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(e.Caller, "/pgxlogger_test.go:") {
+			t.Errorf("%s: got caller %q, want the Log caller", e.Msg, e.Caller)
+		}
+	}
+	if n != 2 {
+		t.Errorf("got %d entries, want 2", n)
+	}
+}
@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PromHTTPLogger adapts a Logger to the prometheus promhttp.Logger
+// interface, logging the scrape errors:
+//
+//	http.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{
+//		ErrorLog: logger.NewPromHTTPLogger(lg),
+//	}))
+//
+// The entries have a "logger" field with the "promhttp" value.
+type PromHTTPLogger struct {
+	l Logger
+}
+
+// NewPromHTTPLogger returns a PromHTTPLogger writing through l,
+// reporting the caller of Println.
+func NewPromHTTPLogger(l Logger) PromHTTPLogger {
+	return PromHTTPLogger{l: l.Named("promhttp").WithCallerSkip(1)}
+}
+
+// Println logs the values as an error, joined as fmt.Sprintln does.
+func (p PromHTTPLogger) Println(v ...interface{}) {
+	p.l.Error(strings.TrimSuffix(fmt.Sprintln(v...), "\n"))
+}
+
+// PromErrorLogger adapts a Logger to the key/value Log method of the
+// go-kit logger used by the older Prometheus components, e.g. the
+// exporter toolkit, as well as to the promhttp.Logger interface:
+//
+//	web.ListenAndServe(server, flags, logger.NewPromErrorLogger(lg))
+//
+// The "level" value selects the Level, error by default, the "msg"
+// value is the message and the other pairs are logged as fields.
+type PromErrorLogger struct {
+	l Logger
+}
+
+// NewPromErrorLogger returns a PromErrorLogger writing through l,
+// reporting the caller of its methods.
+func NewPromErrorLogger(l Logger) PromErrorLogger {
+	return PromErrorLogger{l: l.Named("prometheus").WithCallerSkip(1)}
+}
+
+// Log logs the key/value pairs, it never fails.
+func (p PromErrorLogger) Log(keyvals ...interface{}) error {
+	level := ErrorLevel
+	var msg string
+	fields := make([]interface{}, 0, len(keyvals))
+	for i := 0; i < len(keyvals); i += 2 {
+		var v interface{} = MissingValue
+		if i+1 < len(keyvals) {
+			v = keyvals[i+1]
+		}
+		switch fmt.Sprint(keyvals[i]) {
+		case "level":
+			level = promLevel(fmt.Sprint(v))
+			continue
+		case "msg":
+			msg = fmt.Sprint(v)
+			continue
+		}
+		fields = append(fields, keyvals[i], v)
+	}
+	l := p.l
+	if len(fields) > 0 {
+		l = l.With(fields...)
+	}
+	l.Log(level, msg)
+	return nil
+}
+
+// Println logs the values as an error, joined as fmt.Sprintln does.
+func (p PromErrorLogger) Println(v ...interface{}) {
+	p.l.Error(strings.TrimSuffix(fmt.Sprintln(v...), "\n"))
+}
+
+// promLevel returns the Level of a go-kit level value, error by default.
+func promLevel(s string) Level {
+	switch strings.ToLower(s) {
+	case "debug":
+		return DebugLevel
+	case "info":
+		return InfoLevel
+	case "warn", "warning":
+		return WarningLevel
+	}
+	return ErrorLevel
+}
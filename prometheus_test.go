@@ -0,0 +1,67 @@
+package logger_test
+
+import (
+	"errors"
+	"testing"
+
+	logger "github.com/Aibier/go-logger"
+)
+
+func TestPromHTTPLogger(t *testing.T) {
+	rec := logger.NewRecorder()
+	logger.NewPromHTTPLogger(logger.NewWithWriter(logger.Config{}, rec)).Println("error gathering metrics:", errors.New("collector failed"))
+	e, ok := rec.LastEntry()
+	if !ok || e.Level != logger.ErrorLevel || e.Message() != "error gathering metrics: collector failed" {
+		t.Fatalf("got entry %v", e)
+	}
+	if v, _ := e.Field(logger.NameFieldKey); v != "promhttp" {
+		t.Errorf("got logger %v", v)
+	}
+}
+
+func TestPromErrorLogger(t *testing.T) {
+	rec := logger.NewRecorder()
+	pl := logger.NewPromErrorLogger(logger.NewWithWriter(logger.Config{Level: logger.DebugLevel}, rec))
+	tests := []struct {
+		keyvals []interface{}
+		level   logger.Level
+		msg     string
+		fields  map[string]interface{}
+	}{
+		{[]interface{}{"level", "info", "msg", "Listening on", "address", ":9100"}, logger.InfoLevel, "Listening on", map[string]interface{}{"address": ":9100"}},
+		{[]interface{}{"level", "WARN", "msg", "slow"}, logger.WarningLevel, "slow", nil},
+		{[]interface{}{"level", "debug", "msg", "scrape"}, logger.DebugLevel, "scrape", nil},
+		{[]interface{}{"msg", "failed", "err", "tls"}, logger.ErrorLevel, "failed", map[string]interface{}{"err": "tls"}},
+		{[]interface{}{"msg", "odd", "key"}, logger.ErrorLevel, "odd", map[string]interface{}{"key": logger.MissingValue}},
+	}
+	for _, tt := range tests {
+		rec.Reset()
+		if err := pl.Log(tt.keyvals...); err != nil {
+			t.Errorf("%v: got error %v", tt.keyvals, err)
+		}
+		e, ok := rec.LastEntry()
+		if !ok || e.Level != tt.level || e.Message() != tt.msg {
+			t.Errorf("%v: got entry %v", tt.keyvals, e)
+			continue
+		}
+		if v, _ := e.Field(logger.NameFieldKey); v != "prometheus" {
+			t.Errorf("%v: got logger %v", tt.keyvals, v)
+		}
+		for k, want := range tt.fields {
+			if v, _ := e.Field(k); v != want {
+				t.Errorf("%v: %s: got %v, want %v", tt.keyvals, k, v, want)
+			}
+		}
+		if _, ok := e.Field("level"); ok {
+			t.Errorf("%v: the level pair was logged as a field", tt.keyvals)
+		}
+	}
+}
+
+func TestPrometheusLoggersCaller(t *testing.T) {
+	lg, entries := newJSONLogger(t)
+	logger.NewPromHTTPLogger(lg).Println("error")
+	logger.NewPromErrorLogger(lg).Println("error")
+	logger.NewPromErrorLogger(lg).Log("level", "info", "msg", "Listening on")
+	checkCallers(t, entries(), 3, "prometheus_test.go")
+}
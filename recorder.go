@@ -1,8 +1,13 @@
 package logger
 
 import (
-	"bytes"
 	"fmt"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
 // Recorder is a writer that will record all the log
@@ -10,11 +15,121 @@ import (
 // It is useful for checking that the expected entries
 // are being logged.
 type Recorder struct {
-	fields     []interface{}
-	syncCalled bool
+	mu          sync.Mutex
+	fields      []interface{}
+	syncCount   int
+	syncCallers []runtime.Frame
 
-	parent  *Recorder
-	entries []LogEntry
+	parent   *Recorder
+	entries  []LogEntry
+	head     int
+	capacity int
+	dropped  uint64
+	seq      uint64
+
+	maxBytes int
+	bytes    int
+	bytesCap int
+	notice   *LogEntry
+
+	hooks         []func(LogEntry)
+	notifyDropped uint64
+
+	expectedErrors int
+
+	strict       bool
+	fatalHandler func(LogEntry)
+
+	clock  Clock
+	caller bool
+
+	// callerSkip the additional frames to skip, see WithCallerSkip.
+	callerSkip int
+
+	// time timestamps the entries when set, see WithTime.
+	time time.Time
+}
+
+// notifyBufferSize is the buffer size of the Notify channels.
+const notifyBufferSize = 128
+
+// RecorderOption configures a Recorder created with NewRecorder.
+type RecorderOption func(*Recorder)
+
+// WithCapacity limits the number of retained entries to n,
+// once full the oldest entries are dropped.
+// A value lower or equal to zero means unbounded.
+func WithCapacity(n int) RecorderOption {
+	return func(rec *Recorder) {
+		rec.capacity = n
+	}
+}
+
+// WithStrictSemantics makes the recorder emulate the production writer:
+// PanicLevel entries panic with a RecordedPanic value, rather than the
+// message the Logger panics with, and FatalLevel entries call the fatal
+// handler, which panics with a RecordedFatal value by default. The entry
+// is always recorded first.
+func WithStrictSemantics() RecorderOption {
+	return func(rec *Recorder) {
+		rec.strict = true
+	}
+}
+
+// WithFatalHandler sets the function called on FatalLevel entries
+// when the recorder uses strict semantics.
+func WithFatalHandler(fn func(LogEntry)) RecorderOption {
+	return func(rec *Recorder) {
+		rec.fatalHandler = fn
+	}
+}
+
+// WithMaxBytes limits the approximate memory used by the recorded entries.
+// Once exceeded, the recorder keeps the current number of entries dropping
+// the oldest ones, and a single warning entry noting it is kept as the
+// first entry.
+func WithMaxBytes(n int) RecorderOption {
+	return func(rec *Recorder) {
+		rec.maxBytes = n
+	}
+}
+
+// WithCaller captures the caller of every recorded entry.
+// It is disabled by default as getting the caller has a cost.
+func WithCaller() RecorderOption {
+	return func(rec *Recorder) {
+		rec.caller = true
+	}
+}
+
+// RecordedPanic is the panic value of a strict recorder
+// on PanicLevel entries.
+type RecordedPanic struct {
+	Entry LogEntry
+}
+
+func (p RecordedPanic) Error() string {
+	return p.Entry.Message()
+}
+
+// RecordedFatal is the panic value of a strict recorder
+// on FatalLevel entries when no fatal handler is set.
+type RecordedFatal struct {
+	Entry LogEntry
+}
+
+func (f RecordedFatal) Error() string {
+	return f.Entry.Message()
+}
+
+// NewRecorder creates a new recorder with the given options.
+// The zero-value Recorder is an unbounded recorder ready to use.
+func NewRecorder(opts ...RecorderOption) *Recorder {
+	rec := &Recorder{}
+	for _, opt := range opts {
+		opt(rec)
+	}
+	return rec
 }
 
 // LogEntry is holds a single log entry information.
@@ -23,6 +138,92 @@ type LogEntry struct {
 	Str    string
 	Args   []interface{}
 	Fields []interface{}
+	// KeysAndValues the key/value pairs of an entry
+	// logged by Logw, after its Fields.
+	KeysAndValues []interface{}
+	Time          time.Time
+	Caller        EntryCaller
+}
+
+// EntryCaller holds the location of the code that logged an entry.
+type EntryCaller struct {
+	Defined  bool
+	File     string
+	Line     int
+	Function string
+}
+
+// String returns the caller as dir/file:line, the same way
+// the zap writer reports it.
+func (c EntryCaller) String() string {
+	if !c.Defined {
+		return "undefined"
+	}
+	file := c.File
+	if i := strings.LastIndexByte(file, '/'); i >= 0 {
+		if j := strings.LastIndexByte(file[:i], '/'); j >= 0 {
+			file = file[j+1:]
+		}
+	}
+	return file + ":" + strconv.Itoa(c.Line)
+}
+
+// Message returns the rendered entry message, as the zap sugared
+// logger does: printf compatible entries are formatted with their args,
+// or returned verbatim without args, otherwise the args are concatenated.
+func (e LogEntry) Message() string {
+	if e.Str != "" {
+		if len(e.Args) == 0 {
+			return e.Str
+		}
+		return fmt.Sprintf(e.Str, e.Args...)
+	}
+	return fmt.Sprint(e.Args...)
+}
+
+// Field returns the value of the field with the given key.
+// If the key is repeated the last value is returned.
+// Keys that are not strings are compared using their fmt.Sprint
+// representation.
+func (e LogEntry) Field(key string) (interface{}, bool) {
+	var (
+		value interface{}
+		found bool
+	)
+	fields := e.allFields()
+	for i := 0; i+1 < len(fields); i += 2 {
+		if fieldKey(fields[i]) == key {
+			value, found = fields[i+1], true
+		}
+	}
+	return value, found
+}
+
+// FieldMap returns the entry fields as a map, later values win
+// for repeated keys. A dangling key without value is dropped.
+// Keys that are not strings are converted using fmt.Sprint.
+func (e LogEntry) FieldMap() map[string]interface{} {
+	fields := e.allFields()
+	m := make(map[string]interface{}, len(fields)/2)
+	for i := 0; i+1 < len(fields); i += 2 {
+		m[fieldKey(fields[i])] = fields[i+1]
+	}
+	return m
+}
+
+// allFields returns the entry Fields followed by its KeysAndValues.
+func (e LogEntry) allFields() []interface{} {
+	if len(e.KeysAndValues) == 0 {
+		return e.Fields
+	}
+	return append(e.Fields[:len(e.Fields):len(e.Fields)], e.KeysAndValues...)
+}
+
+func fieldKey(k interface{}) string {
+	if s, ok := k.(string); ok {
+		return s
+	}
+	return fmt.Sprint(k)
 }
 
 // With return a new recorder with custom fields added.
@@ -33,70 +234,291 @@ func (rec *Recorder) With(fields ...interface{}) Writer {
 	return rec.clone(all)
 }
 
+// WithTime returns a recorder timestamping the entries with t.
+func (rec *Recorder) WithTime(t time.Time) Writer {
+	cp := rec.clone(rec.fields)
+	cp.time = t
+	return cp
+}
+
+// WithCallerSkip returns a recorder skipping n additional stack
+// frames to report the caller of the entries, see WithCaller.
+func (rec *Recorder) WithCallerSkip(n int) Writer {
+	cp := rec.clone(rec.fields)
+	cp.callerSkip += n
+	return cp
+}
+
 // Log records a new log entry
 func (rec *Recorder) Log(level Level, args ...interface{}) {
-	rec.record(level, "", args...)
+	rec.record(level, "", args, nil)
 }
 
 // Logf records a new printf compatible log entry
 func (rec *Recorder) Logf(level Level, str string, args ...interface{}) {
-	rec.record(level, str, args...)
+	rec.record(level, str, args, nil)
+}
+
+// Logw records a new log entry with key/value pairs, kept
+// in the entry KeysAndValues. The message is kept verbatim.
+func (rec *Recorder) Logw(level Level, msg string, keysAndValues ...interface{}) {
+	rec.record(level, msg, nil, keysAndValues)
 }
 
 // Sync signal the recorder that the sync operation has been triggered.
 func (rec *Recorder) Sync() {
-	rec.top().syncCalled = true
+	frame := externalFrame()
+	top := rec.top()
+	top.mu.Lock()
+	top.syncCount++
+	top.syncCallers = append(top.syncCallers, frame)
+	top.mu.Unlock()
 }
 
 // SyncCalled returns if the Sync operation was called.
 func (rec *Recorder) SyncCalled() bool {
-	return rec.top().syncCalled
+	return rec.SyncCount() > 0
+}
+
+// SyncCount returns how many times the Sync operation was called.
+func (rec *Recorder) SyncCount() int {
+	top := rec.top()
+	top.mu.Lock()
+	defer top.mu.Unlock()
+	return top.syncCount
+}
+
+// SyncCallers returns the caller frame of every Sync call, the first
+// one outside of this package, useful to debug writers syncing more
+// than once.
+func (rec *Recorder) SyncCallers() []runtime.Frame {
+	top := rec.top()
+	top.mu.Lock()
+	defer top.mu.Unlock()
+	return append([]runtime.Frame(nil), top.syncCallers...)
 }
 
 // Entries returns the recorded log entries.
 func (rec *Recorder) Entries() []LogEntry {
-	return rec.top().entries
+	top := rec.top()
+	top.mu.Lock()
+	defer top.mu.Unlock()
+	return top.ordered()
+}
+
+// OnRecord registers a function that will be called synchronously
+// every time an entry is recorded. It can be registered at any moment,
+// entries recorded before the registration are not notified.
+func (rec *Recorder) OnRecord(fn func(LogEntry)) {
+	top := rec.top()
+	top.mu.Lock()
+	top.hooks = append(top.hooks, fn)
+	top.mu.Unlock()
 }
 
-// Dump will dump all the entries.
-func (rec *Recorder) Dump() []byte {
-	var b bytes.Buffer
+// Notify returns a buffered channel that receives every entry recorded
+// after the call. Sending never blocks the logger, entries that don't fit
+// in the buffer are dropped and counted in NotifyDropped.
+// Usage:
+//
+//	select {
+//	case e := <-rec.Notify():
+//	case <-time.After(time.Second):
+//	}
+func (rec *Recorder) Notify() <-chan LogEntry {
+	top := rec.top()
+	ch := make(chan LogEntry, notifyBufferSize)
+	top.OnRecord(func(e LogEntry) {
+		select {
+		case ch <- e:
+		default:
+			top.mu.Lock()
+			top.notifyDropped++
+			top.mu.Unlock()
+		}
+	})
+	return ch
+}
+
+// NotifyDropped returns the number of entries that couldn't be sent
+// to the Notify channels because their buffer was full.
+func (rec *Recorder) NotifyDropped() uint64 {
+	top := rec.top()
+	top.mu.Lock()
+	defer top.mu.Unlock()
+	return top.notifyDropped
+}
+
+// SetClock sets the clock used to timestamp the recorded entries.
+// The system clock is used by default.
+func (rec *Recorder) SetClock(c Clock) {
+	top := rec.top()
+	top.mu.Lock()
+	top.clock = c
+	top.mu.Unlock()
+}
+
+// ApproxBytes returns the approximate memory used by the retained entries.
+func (rec *Recorder) ApproxBytes() int {
+	top := rec.top()
+	top.mu.Lock()
+	defer top.mu.Unlock()
+	return top.bytes
+}
+
+// Dropped returns the number of entries dropped because
+// the recorder capacity was reached.
+func (rec *Recorder) Dropped() uint64 {
+	top := rec.top()
+	top.mu.Lock()
+	defer top.mu.Unlock()
+	return top.dropped
+}
+
+// LastEntry returns the most recent log entry, if any.
+func (rec *Recorder) LastEntry() (LogEntry, bool) {
+	top := rec.top()
+	top.mu.Lock()
+	defer top.mu.Unlock()
+	if len(top.entries) == 0 {
+		return LogEntry{}, false
+	}
+	last := top.head - 1
+	if last < 0 {
+		last = len(top.entries) - 1
+	}
+	return top.entries[last], true
+}
+
+// TakeAll returns the recorded log entries and clears them,
+// so following assertions only see new entries.
+// Nothing else is reset, the sync state is kept.
+func (rec *Recorder) TakeAll() []LogEntry {
+	top := rec.top()
+	top.mu.Lock()
+	defer top.mu.Unlock()
+	entries := top.ordered()
+	top.clear()
+	return entries
+}
+
+// Mark is an opaque position in the recorded entries.
+type Mark struct {
+	seq uint64
+}
+
+// Mark returns the current position, so the entries recorded
+// after it can be retrieved with EntriesSince.
+func (rec *Recorder) Mark() Mark {
+	top := rec.top()
+	top.mu.Lock()
+	defer top.mu.Unlock()
+	return Mark{seq: top.seq}
+}
+
+// EntriesSince returns the retained entries recorded after the mark.
+// Entries dropped because of the capacity or cleared with TakeAll or Reset
+// are not returned, see TruncatedSince.
+func (rec *Recorder) EntriesSince(m Mark) []LogEntry {
+	top := rec.top()
+	top.mu.Lock()
+	defer top.mu.Unlock()
+	entries := top.ordered()
+	if n := top.seq - m.seq; n < uint64(len(entries)) {
+		entries = entries[len(entries)-int(n):]
+	}
+	return entries
+}
+
+// TruncatedSince returns if any entry recorded after the mark
+// is no longer retained.
+func (rec *Recorder) TruncatedSince(m Mark) bool {
+	top := rec.top()
+	top.mu.Lock()
+	defer top.mu.Unlock()
+	return top.seq-m.seq > uint64(len(top.entries))
+}
+
+// CountSince returns the number of retained entries with the given level
+// recorded after the mark.
+func (rec *Recorder) CountSince(m Mark, level Level) int {
+	var count int
+	for _, e := range rec.EntriesSince(m) {
+		if e.Level == level {
+			count++
+		}
+	}
+	return count
+}
+
+// EntriesByField groups the entries by the value of the field with the
+// given key, converted using fmt.Sprint. Entries without the field are
+// not included.
+func (rec *Recorder) EntriesByField(key string) map[string][]LogEntry {
+	groups := make(map[string][]LogEntry)
 	for _, e := range rec.Entries() {
-		b.WriteByte('[')
-		b.WriteString(fmt.Sprintf("%-7s", e.Level.String()))
-		b.WriteByte(']')
+		if v, ok := e.Field(key); ok {
+			k := fmt.Sprint(v)
+			groups[k] = append(groups[k], e)
+		}
+	}
+	return groups
+}
 
-		if e.Str != "" {
-			b.WriteByte(' ')
-			b.WriteString(fmt.Sprintf(e.Str, e.Args...))
+// ContainsMessage returns if any entry message contains substr.
+func (rec *Recorder) ContainsMessage(substr string) bool {
+	for _, e := range rec.Entries() {
+		if strings.Contains(e.Message(), substr) {
+			return true
 		}
-		if e.Str == "" && len(e.Args) > 0 {
-			b.WriteByte(' ')
-			b.WriteByte('[')
-			for i, a := range e.Args {
-				if i > 0 {
-					b.WriteByte(',')
-					b.WriteByte(' ')
-				}
-				b.WriteString(fmt.Sprint(a))
-			}
-			b.WriteByte(']')
+	}
+	return false
+}
+
+// ContainsAt returns if any entry with the given level
+// has a message containing substr.
+func (rec *Recorder) ContainsAt(level Level, substr string) bool {
+	for _, e := range rec.Entries() {
+		if e.Level == level && strings.Contains(e.Message(), substr) {
+			return true
 		}
+	}
+	return false
+}
 
-		b.WriteByte(' ')
-		b.WriteByte('{')
-		for i, f := range e.Fields {
-			if i > 0 {
-				b.WriteByte(',')
-				b.WriteByte(' ')
-			}
-			b.WriteString(fmt.Sprint(f))
+// Match returns the entries whose message matches the regular expression.
+func (rec *Recorder) Match(re *regexp.Regexp) []LogEntry {
+	var matched []LogEntry
+	for _, e := range rec.Entries() {
+		if re.MatchString(e.Message()) {
+			matched = append(matched, e)
+		}
+	}
+	return matched
+}
+
+// MatchAt returns the entries with the given level whose message
+// matches the regular expression.
+func (rec *Recorder) MatchAt(level Level, re *regexp.Regexp) []LogEntry {
+	var matched []LogEntry
+	for _, e := range rec.Entries() {
+		if e.Level == level && re.MatchString(e.Message()) {
+			matched = append(matched, e)
 		}
-		b.WriteByte('}')
-		b.WriteByte('\n')
 	}
+	return matched
+}
 
-	return b.Bytes()
+// Reset clears the recorded entries, the dropped counter and the sync state.
+// Loggers derived with With keep working with the same fields.
+func (rec *Recorder) Reset() {
+	top := rec.top()
+	top.mu.Lock()
+	top.clear()
+	top.dropped = 0
+	top.syncCount = 0
+	top.syncCallers = nil
+	top.mu.Unlock()
 }
 
 // top will get the top-most recorder.
@@ -112,21 +534,158 @@ func (rec *Recorder) top() *Recorder {
 	return top
 }
 
-func (rec *Recorder) record(level Level, str string, args ...interface{}) {
+func (rec *Recorder) record(level Level, str string, args, keysAndValues []interface{}) {
 	var top = rec.top()
 	e := LogEntry{
-		Level:  level,
-		Str:    str,
-		Args:   args,
-		Fields: make([]interface{}, len(rec.fields)),
+		Level:         level,
+		Str:           str,
+		Args:          args,
+		Fields:        make([]interface{}, len(rec.fields)),
+		KeysAndValues: keysAndValues,
 	}
 	copy(e.Fields, rec.fields)
-	top.entries = append(top.entries, e)
+
+	top.mu.Lock()
+	if top.caller {
+		e.Caller = recordCaller(rec.callerSkip)
+	}
+	switch {
+	case !rec.time.IsZero():
+		e.Time = rec.time
+	case top.clock != nil:
+		e.Time = top.clock.Now()
+	default:
+		e.Time = time.Now()
+	}
+	top.add(e)
+	hooks := top.hooks
+	top.mu.Unlock()
+
+	for _, fn := range hooks {
+		fn(e)
+	}
+
+	if !top.strict {
+		return
+	}
+	switch level {
+	case PanicLevel:
+		panic(RecordedPanic{Entry: e})
+	case FatalLevel:
+		if top.fatalHandler != nil {
+			top.fatalHandler(e)
+			return
+		}
+		panic(RecordedFatal{Entry: e})
+	}
+}
+
+// recorderCallerSkip is the number of frames to skip to reach the
+// logger caller: recordCaller, record, Recorder.Log, Logger.log and
+// the Logger level method.
+const recorderCallerSkip = 5
+
+// recordCaller returns the caller of the entry skipping
+// skip frames more than recorderCallerSkip.
+func recordCaller(skip int) EntryCaller {
+	pc := make([]uintptr, 1)
+	if runtime.Callers(recorderCallerSkip+skip+1, pc) == 0 {
+		return EntryCaller{}
+	}
+	frame, _ := runtime.CallersFrames(pc).Next()
+	return EntryCaller{
+		Defined:  true,
+		File:     frame.File,
+		Line:     frame.Line,
+		Function: frame.Function,
+	}
+}
+
+// add appends a new entry, overwriting the oldest one when
+// the capacity is reached. The caller must hold the lock.
+func (rec *Recorder) add(e LogEntry) {
+	rec.seq++
+	rec.push(e)
+	if rec.maxBytes <= 0 || rec.bytesCap > 0 || rec.bytes <= rec.maxBytes {
+		return
+	}
+
+	rec.bytesCap = len(rec.entries)
+	rec.notice = &LogEntry{
+		Level: WarningLevel,
+		Str:   "recorder memory cap of %d bytes reached, dropping the oldest entries",
+		Args:  []interface{}{rec.maxBytes},
+		Time:  e.Time,
+	}
+	rec.bytes += entrySize(*rec.notice)
+}
+
+// push stores an entry in the ring buffer.
+// The caller must hold the lock.
+func (rec *Recorder) push(e LogEntry) {
+	limit := rec.capacity
+	if rec.bytesCap > 0 && (limit <= 0 || rec.bytesCap < limit) {
+		limit = rec.bytesCap
+	}
+
+	rec.bytes += entrySize(e)
+	if limit <= 0 || len(rec.entries) < limit {
+		rec.entries = append(rec.entries, e)
+		return
+	}
+	rec.bytes -= entrySize(rec.entries[rec.head])
+	rec.entries[rec.head] = e
+	rec.head = (rec.head + 1) % limit
+	rec.dropped++
+}
+
+// clear removes the retained entries.
+// The caller must hold the lock.
+func (rec *Recorder) clear() {
+	rec.entries = nil
+	rec.head = 0
+	rec.bytes = 0
+	rec.bytesCap = 0
+	rec.notice = nil
+}
+
+// entryOverhead is the estimated memory used by an entry
+// without taking into account its message, args and fields.
+const entryOverhead = 256
+
+// entrySize returns the approximate memory used by an entry.
+func entrySize(e LogEntry) int {
+	size := entryOverhead + len(e.Str)
+	for _, vs := range [][]interface{}{e.Args, e.Fields, e.KeysAndValues} {
+		for _, v := range vs {
+			size += 16
+			switch v := v.(type) {
+			case string:
+				size += len(v)
+			case []byte:
+				size += len(v)
+			}
+		}
+	}
+	return size
+}
+
+// ordered returns a copy of the retained entries from the oldest
+// to the newest. The caller must hold the lock.
+func (rec *Recorder) ordered() []LogEntry {
+	entries := make([]LogEntry, 0, len(rec.entries)+1)
+	if rec.notice != nil {
+		entries = append(entries, *rec.notice)
+	}
+	entries = append(entries, rec.entries[rec.head:]...)
+	return append(entries, rec.entries[:rec.head]...)
 }
 
 func (rec *Recorder) clone(fields []interface{}) *Recorder {
 	cp := Recorder{
-		parent: rec,
+		parent:     rec,
+		time:       rec.time,
+		callerSkip: rec.callerSkip,
 	}
 	cp.fields = append(cp.fields, fields...)
 	return &cp
@@ -0,0 +1,132 @@
+package logger
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// AssertLogged fails the test if no entry with the given level
+// contains msgSubstring in its message.
+// Printf compatible entries are formatted before matching.
+func (rec *Recorder) AssertLogged(t testing.TB, level Level, msgSubstring string) {
+	t.Helper()
+	for _, e := range rec.Entries() {
+		if e.Level == level && strings.Contains(e.Message(), msgSubstring) {
+			return
+		}
+	}
+	t.Errorf("no %s entry containing %q was logged\n%s", level, msgSubstring, rec.failureDump())
+}
+
+// AssertField fails the test if no entry has a field with the given
+// key and a value deeply equal to want.
+func (rec *Recorder) AssertField(t testing.TB, key string, want interface{}) {
+	t.Helper()
+	for _, e := range rec.Entries() {
+		if v, ok := e.Field(key); ok && reflect.DeepEqual(v, want) {
+			return
+		}
+	}
+	t.Errorf("no entry with field %s=%v was logged\n%s", key, want, rec.failureDump())
+}
+
+// AssertNoEntriesAbove fails the test if any entry with a level
+// greater than the given one was logged.
+func (rec *Recorder) AssertNoEntriesAbove(t testing.TB, level Level) {
+	t.Helper()
+	for _, e := range rec.Entries() {
+		if e.Level > level {
+			t.Errorf("unexpected entries above %s level were logged\n%s", level, rec.failureDump())
+			return
+		}
+	}
+}
+
+// AssertEntryCount fails the test if the number of entries logged
+// with the given level is not n.
+func (rec *Recorder) AssertEntryCount(t testing.TB, level Level, n int) {
+	t.Helper()
+	var count int
+	for _, e := range rec.Entries() {
+		if e.Level == level {
+			count++
+		}
+	}
+	if count != n {
+		t.Errorf("expected %d %s entries, got %d\n%s", n, level, count, rec.failureDump())
+	}
+}
+
+// AssertNotLogged fails the test if any entry message or field value
+// contains substr. Field values are compared using fmt.Sprint.
+func (rec *Recorder) AssertNotLogged(t testing.TB, substr string) {
+	t.Helper()
+	for _, e := range rec.Entries() {
+		if strings.Contains(e.Message(), substr) {
+			t.Errorf("unexpected entry containing %q was logged\n%s", substr, rec.failureDump())
+			return
+		}
+		fields := e.allFields()
+		for i := 1; i < len(fields); i += 2 {
+			if strings.Contains(fmt.Sprint(fields[i]), substr) {
+				t.Errorf("unexpected field %v containing %q was logged\n%s", fields[i-1], substr, rec.failureDump())
+				return
+			}
+		}
+	}
+}
+
+// AssertNoField fails the test if any entry has a field with the given key.
+func (rec *Recorder) AssertNoField(t testing.TB, key string) {
+	t.Helper()
+	for _, e := range rec.Entries() {
+		if _, ok := e.Field(key); ok {
+			t.Errorf("unexpected entry with field %s was logged\n%s", key, rec.failureDump())
+			return
+		}
+	}
+}
+
+// AssertNoEntries fails the test if any entry with the given level was logged.
+func (rec *Recorder) AssertNoEntries(t testing.TB, level Level) {
+	t.Helper()
+	for _, e := range rec.Entries() {
+		if e.Level == level {
+			t.Errorf("unexpected %s entries were logged\n%s", level, rec.failureDump())
+			return
+		}
+	}
+}
+
+// AssertNotLoggedWithin waits for d before calling AssertNotLogged,
+// so entries logged asynchronously are taken into account.
+func (rec *Recorder) AssertNotLoggedWithin(t testing.TB, substr string, d time.Duration) {
+	t.Helper()
+	time.Sleep(d)
+	rec.AssertNotLogged(t, substr)
+}
+
+// AssertNoFieldWithin waits for d before calling AssertNoField,
+// so entries logged asynchronously are taken into account.
+func (rec *Recorder) AssertNoFieldWithin(t testing.TB, key string, d time.Duration) {
+	t.Helper()
+	time.Sleep(d)
+	rec.AssertNoField(t, key)
+}
+
+// AssertNoEntriesWithin waits for d before calling AssertNoEntries,
+// so entries logged asynchronously are taken into account.
+func (rec *Recorder) AssertNoEntriesWithin(t testing.TB, level Level, d time.Duration) {
+	t.Helper()
+	time.Sleep(d)
+	rec.AssertNoEntries(t, level)
+}
+
+// ResetOnCleanup registers Reset to be called when the test
+// and all its subtests complete.
+func (rec *Recorder) ResetOnCleanup(t testing.TB) {
+	t.Cleanup(rec.Reset)
+}
@@ -0,0 +1,94 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// assertCase is an assertion call expected to pass,
+// or to fail with a message starting with failed.
+type assertCase struct {
+	name   string
+	assert func(testing.TB)
+	failed string
+}
+
+func checkAssertCases(t *testing.T, cases []assertCase) {
+	t.Helper()
+	for _, c := range cases {
+		tb := &fakeTB{}
+		c.assert(tb)
+		switch {
+		case c.failed == "" && len(tb.errors) > 0:
+			t.Errorf("%s: got failures %q", c.name, tb.errors)
+		case c.failed != "" && (len(tb.errors) != 1 || !strings.HasPrefix(tb.errors[0], c.failed)):
+			t.Errorf("%s: got failures %q, want %q", c.name, tb.errors, c.failed)
+		}
+	}
+}
+
+func TestRecorderAbsenceAssertions(t *testing.T) {
+	rec := NewRecorder()
+	lg := NewWithWriter(Config{}, rec)
+	lg.Infof("user %s logged in", "bob")
+	lg.Warnw("retrying", "token", []string{"tok_abc"}, "attempt", 2)
+
+	checkAssertCases(t, []assertCase{
+		{"not logged", func(tb testing.TB) { rec.AssertNotLogged(tb, "hunter2") }, ""},
+		{"logged in formatted message", func(tb testing.TB) { rec.AssertNotLogged(tb, "user bob") },
+			`unexpected entry containing "user bob" was logged` + "\n[warning] retrying"},
+		{"logged in field value", func(tb testing.TB) { rec.AssertNotLogged(tb, "tok_abc") },
+			`unexpected field token containing "tok_abc" was logged`},
+		{"field key not matched", func(tb testing.TB) { rec.AssertNotLogged(tb, "attempt") }, ""},
+		{"no field", func(tb testing.TB) { rec.AssertNoField(tb, "password") }, ""},
+		{"field", func(tb testing.TB) { rec.AssertNoField(tb, "token") }, "unexpected entry with field token was logged\n"},
+		{"no entries", func(tb testing.TB) { rec.AssertNoEntries(tb, ErrorLevel) }, ""},
+		{"entries", func(tb testing.TB) { rec.AssertNoEntries(tb, WarningLevel) }, "unexpected warning entries were logged\n"},
+	})
+
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		lg.Errorw("late", "password", "hunter2")
+	}()
+	checkAssertCases(t, []assertCase{
+		{"not logged within", func(tb testing.TB) { rec.AssertNotLoggedWithin(tb, "hunter2", 100*time.Millisecond) },
+			`unexpected field password containing "hunter2" was logged`},
+		{"no field within", func(tb testing.TB) { rec.AssertNoFieldWithin(tb, "password", 0) }, "unexpected entry with field password"},
+		{"no entries within", func(tb testing.TB) { rec.AssertNoEntriesWithin(tb, ErrorLevel, 0) }, "unexpected error entries"},
+		{"no fatal entries within", func(tb testing.TB) { rec.AssertNoEntriesWithin(tb, FatalLevel, time.Millisecond) }, ""},
+	})
+}
+
+func TestRecorderAssertions(t *testing.T) {
+	rec := NewRecorder()
+	lg := NewWithWriter(Config{}, rec)
+	lg.Infof("user %s logged in", "bob")
+	lg.Infow("request", "status", 200, "tags", []string{"a", "b"})
+	lg.Warn("slow request")
+
+	checkAssertCases(t, []assertCase{
+		{"logged", func(tb testing.TB) { rec.AssertLogged(tb, InfoLevel, "user bob") }, ""},
+		{"logged empty substring", func(tb testing.TB) { rec.AssertLogged(tb, WarningLevel, "") }, ""},
+		{"logged other level", func(tb testing.TB) { rec.AssertLogged(tb, ErrorLevel, "slow") },
+			`no error entry containing "slow" was logged` +
+				"\n[warning] slow request {}\n(2 entries below warning level hidden)\n"},
+		{"not logged", func(tb testing.TB) { rec.AssertLogged(tb, InfoLevel, "alice") },
+			`no info entry containing "alice" was logged`},
+		{"field", func(tb testing.TB) { rec.AssertField(tb, "status", 200) }, ""},
+		{"deep equal field", func(tb testing.TB) { rec.AssertField(tb, "tags", []string{"a", "b"}) }, ""},
+		{"field other value", func(tb testing.TB) { rec.AssertField(tb, "status", 500) },
+			"no entry with field status=500 was logged\n"},
+		{"field other type", func(tb testing.TB) { rec.AssertField(tb, "status", int64(200)) },
+			"no entry with field status=200 was logged\n"},
+		{"missing field", func(tb testing.TB) { rec.AssertField(tb, "user", "bob") },
+			"no entry with field user=bob was logged\n"},
+		{"no entries above warning", func(tb testing.TB) { rec.AssertNoEntriesAbove(tb, WarningLevel) }, ""},
+		{"entries above info", func(tb testing.TB) { rec.AssertNoEntriesAbove(tb, InfoLevel) },
+			"unexpected entries above info level were logged\n"},
+		{"info count", func(tb testing.TB) { rec.AssertEntryCount(tb, InfoLevel, 2) }, ""},
+		{"error count", func(tb testing.TB) { rec.AssertEntryCount(tb, ErrorLevel, 0) }, ""},
+		{"wrong count", func(tb testing.TB) { rec.AssertEntryCount(tb, WarningLevel, 2) },
+			"expected 2 warning entries, got 1\n"},
+	})
+}
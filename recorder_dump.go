@@ -0,0 +1,279 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// dumpConfig holds the Dump settings.
+type dumpConfig struct {
+	time       bool
+	caller     bool
+	sorted     bool
+	paired     bool
+	minLevel   Level
+	onlyFields map[string]bool
+	maxEntries int
+	fromEnd    bool
+}
+
+// DumpOption configures the Dump output.
+type DumpOption func(*dumpConfig)
+
+// DumpTime includes the entry timestamps in the Dump output.
+func DumpTime() DumpOption {
+	return func(c *dumpConfig) {
+		c.time = true
+	}
+}
+
+// DumpCaller includes the entry callers in the Dump output,
+// when captured with the WithCaller recorder option.
+func DumpCaller() DumpOption {
+	return func(c *dumpConfig) {
+		c.caller = true
+	}
+}
+
+// SortedFields renders the entry fields sorted by key.
+func SortedFields() DumpOption {
+	return func(c *dumpConfig) {
+		c.sorted = true
+	}
+}
+
+// PairedFields renders the entry fields as key=value pairs
+// instead of a flat list. A key without value is rendered
+// as key=(MISSING).
+func PairedFields() DumpOption {
+	return func(c *dumpConfig) {
+		c.paired = true
+	}
+}
+
+// MinLevel only renders the entries with the given level or above.
+func MinLevel(level Level) DumpOption {
+	return func(c *dumpConfig) {
+		c.minLevel = level
+	}
+}
+
+// OnlyFields only renders the fields with the given keys.
+func OnlyFields(keys ...string) DumpOption {
+	return func(c *dumpConfig) {
+		c.onlyFields = make(map[string]bool, len(keys))
+		for _, k := range keys {
+			c.onlyFields[k] = true
+		}
+	}
+}
+
+// MaxEntries renders at most n entries, the newest ones
+// when fromEnd is true or the oldest ones otherwise.
+func MaxEntries(n int, fromEnd bool) DumpOption {
+	return func(c *dumpConfig) {
+		c.maxEntries = n
+		c.fromEnd = fromEnd
+	}
+}
+
+// Dump will dump all the entries.
+func (rec *Recorder) Dump(opts ...DumpOption) []byte {
+	var cfg dumpConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return dumpEntries(rec.Entries(), cfg)
+}
+
+// failureDump dumps the entries at WarningLevel or above
+// for assertion failure messages, noting the hidden ones.
+func (rec *Recorder) failureDump() []byte {
+	entries := rec.Entries()
+	b := dumpEntries(entries, dumpConfig{minLevel: WarningLevel})
+	var hidden int
+	for _, e := range entries {
+		if e.Level.orInfo() < WarningLevel {
+			hidden++
+		}
+	}
+	if hidden > 0 {
+		b = append(b, fmt.Sprintf("(%d entries below %s level hidden)\n", hidden, WarningLevel)...)
+	}
+	return b
+}
+
+func dumpEntries(all []LogEntry, cfg dumpConfig) []byte {
+	var entries []LogEntry
+	for _, e := range all {
+		if e.Level.orInfo() >= cfg.minLevel {
+			entries = append(entries, e)
+		}
+	}
+	if cfg.maxEntries > 0 && len(entries) > cfg.maxEntries {
+		if cfg.fromEnd {
+			entries = entries[len(entries)-cfg.maxEntries:]
+		} else {
+			entries = entries[:cfg.maxEntries]
+		}
+	}
+
+	var b bytes.Buffer
+	for _, e := range entries {
+		if cfg.time {
+			b.WriteString(e.Time.Format(time.RFC3339Nano))
+			b.WriteByte(' ')
+		}
+		b.WriteByte('[')
+		b.WriteString(fmt.Sprintf("%-7s", e.Level.String()))
+		b.WriteByte(']')
+
+		if cfg.caller {
+			b.WriteByte(' ')
+			b.WriteString(e.Caller.String())
+		}
+
+		if msg := e.Message(); msg != "" {
+			b.WriteByte(' ')
+			b.WriteString(msg)
+		}
+
+		fields := e.allFields()
+		if cfg.onlyFields != nil {
+			fields = filterFields(fields, cfg.onlyFields)
+		}
+		if cfg.sorted {
+			fields = sortedFields(fields)
+		}
+
+		b.WriteByte(' ')
+		b.WriteByte('{')
+		if cfg.paired {
+			for i := 0; i < len(fields); i += 2 {
+				if i > 0 {
+					b.WriteByte(',')
+					b.WriteByte(' ')
+				}
+				b.WriteString(fmt.Sprint(fields[i]))
+				b.WriteByte('=')
+				if i+1 < len(fields) {
+					b.WriteString(fmt.Sprint(fields[i+1]))
+				} else {
+					b.WriteString(MissingValue)
+				}
+			}
+		} else {
+			for i, f := range fields {
+				if i > 0 {
+					b.WriteByte(',')
+					b.WriteByte(' ')
+				}
+				b.WriteString(fmt.Sprint(f))
+			}
+		}
+		b.WriteByte('}')
+		b.WriteByte('\n')
+	}
+
+	return b.Bytes()
+}
+
+// filterFields returns the key/value pairs with the given keys.
+func filterFields(fields []interface{}, keys map[string]bool) []interface{} {
+	var filtered []interface{}
+	for i := 0; i+1 < len(fields); i += 2 {
+		if keys[fieldKey(fields[i])] {
+			filtered = append(filtered, fields[i], fields[i+1])
+		}
+	}
+	return filtered
+}
+
+// sortedFields returns the key/value pairs sorted by key,
+// a dangling key is kept at the end.
+func sortedFields(fields []interface{}) []interface{} {
+	pairs := make([][2]interface{}, 0, len(fields)/2)
+	for i := 0; i+1 < len(fields); i += 2 {
+		pairs = append(pairs, [2]interface{}{fields[i], fields[i+1]})
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return fieldKey(pairs[i][0]) < fieldKey(pairs[j][0])
+	})
+
+	sorted := make([]interface{}, 0, len(fields))
+	for _, p := range pairs {
+		sorted = append(sorted, p[0], p[1])
+	}
+	if len(fields)%2 != 0 {
+		sorted = append(sorted, fields[len(fields)-1])
+	}
+	return sorted
+}
+
+// Flatten returns every entry as a map holding the level, the rendered
+// message under msg, the time and caller when available, and the entry
+// fields, later fields winning for repeated keys.
+// Field values are not converted.
+func (rec *Recorder) Flatten() []map[string]interface{} {
+	entries := rec.Entries()
+	out := make([]map[string]interface{}, 0, len(entries))
+	for _, e := range entries {
+		m := map[string]interface{}{
+			"level": e.Level.String(),
+			"msg":   e.Message(),
+		}
+		if !e.Time.IsZero() {
+			m["time"] = e.Time
+		}
+		if e.Caller.Defined {
+			m["caller"] = e.Caller.String()
+		}
+		for k, v := range e.FieldMap() {
+			m[k] = v
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+// jsonEntry is the DumpJSON representation of a log entry.
+type jsonEntry struct {
+	Level   string                     `json:"level"`
+	Message string                     `json:"message"`
+	Fields  map[string]json.RawMessage `json:"fields"`
+	Time    string                     `json:"time,omitempty"`
+}
+
+// DumpJSON will dump all the entries as a JSON array.
+// Field values that can't be encoded as JSON are dumped
+// using their fmt.Sprint representation.
+func (rec *Recorder) DumpJSON() ([]byte, error) {
+	entries := rec.Entries()
+	out := make([]jsonEntry, 0, len(entries))
+	for _, e := range entries {
+		fields := make(map[string]json.RawMessage)
+		for k, v := range e.FieldMap() {
+			b, err := json.Marshal(v)
+			if err != nil {
+				b, err = json.Marshal(fmt.Sprint(v))
+				if err != nil {
+					return nil, err
+				}
+			}
+			fields[k] = b
+		}
+		je := jsonEntry{
+			Level:   e.Level.String(),
+			Message: e.Message(),
+			Fields:  fields,
+		}
+		if !e.Time.IsZero() {
+			je.Time = e.Time.Format(time.RFC3339Nano)
+		}
+		out = append(out, je)
+	}
+	return json.Marshal(out)
+}
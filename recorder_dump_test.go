@@ -0,0 +1,138 @@
+package logger
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestDumpJSON(t *testing.T) {
+	ts := time.Date(2024, 3, 1, 12, 0, 0, 500, time.UTC)
+	rec := NewRecorder()
+	rec.SetClock(NewManualClock(ts))
+	lg := NewWithWriter(Config{}, rec).With("service", "api")
+	lg.Infow("request done", "status", 200, "path", "/users", "ch", make(chan int))
+	lg.Errorf("query %s failed", "q1")
+
+	b, err := rec.DumpJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entries []struct {
+		Level   string
+		Message string
+		Fields  map[string]interface{}
+		Time    string
+	}
+	if err := json.Unmarshal(b, &entries); err != nil {
+		t.Fatalf("got invalid JSON %s: %v", b, err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries: %s", len(entries), b)
+	}
+	e := entries[0]
+	if e.Level != "info" || e.Message != "request done" || e.Time != "2024-03-01T12:00:00.0000005Z" {
+		t.Errorf("got entry %+v", e)
+	}
+	if e.Fields["service"] != "api" || e.Fields["status"] != float64(200) || e.Fields["path"] != "/users" {
+		t.Errorf("got fields %v", e.Fields)
+	}
+	if ch, ok := e.Fields["ch"].(string); !ok || ch == "" {
+		t.Errorf("got channel field %#v, want its fmt.Sprint string", e.Fields["ch"])
+	}
+	if e := entries[1]; e.Level != "error" || e.Message != "query q1 failed" || len(e.Fields) != 1 {
+		t.Errorf("got entry %+v", e)
+	}
+
+	if b, err := NewRecorder().DumpJSON(); err != nil || string(b) != "[]" {
+		t.Errorf("got %s, %v for an empty recorder", b, err)
+	}
+}
+
+func TestDumpOptions(t *testing.T) {
+	rec := NewRecorder()
+	lg := NewWithWriter(Config{}, rec).With("db", "main")
+	lg.Debugw("connecting", "attempt", 1)
+	lg.Infow("connected", "latency", "3ms")
+	lg.Warnw("slow query", "ms", 120, "query", "q1")
+	lg.Errorw("query failed", "error", "boom", "query")
+
+	tests := []struct {
+		name string
+		opts []DumpOption
+		want string
+	}{
+		{"default", nil, "" +
+			"[debug  ] connecting {db, main, attempt, 1}\n" +
+			"[info   ] connected {db, main, latency, 3ms}\n" +
+			"[warning] slow query {db, main, ms, 120, query, q1}\n" +
+			"[error  ] query failed {db, main, error, boom, query, (MISSING)}\n"},
+		{"min level", []DumpOption{MinLevel(WarningLevel)}, "" +
+			"[warning] slow query {db, main, ms, 120, query, q1}\n" +
+			"[error  ] query failed {db, main, error, boom, query, (MISSING)}\n"},
+		{"only fields", []DumpOption{OnlyFields("query", "attempt")}, "" +
+			"[debug  ] connecting {attempt, 1}\n" +
+			"[info   ] connected {}\n" +
+			"[warning] slow query {query, q1}\n" +
+			"[error  ] query failed {query, (MISSING)}\n"},
+		{"max entries", []DumpOption{MaxEntries(1, false)}, "" +
+			"[debug  ] connecting {db, main, attempt, 1}\n"},
+		{"max entries from end", []DumpOption{MaxEntries(3, true), MinLevel(InfoLevel)}, "" +
+			"[info   ] connected {db, main, latency, 3ms}\n" +
+			"[warning] slow query {db, main, ms, 120, query, q1}\n" +
+			"[error  ] query failed {db, main, error, boom, query, (MISSING)}\n"},
+		{"paired and sorted", []DumpOption{PairedFields(), SortedFields(), MinLevel(ErrorLevel)}, "" +
+			"[error  ] query failed {db=main, error=boom, query=(MISSING)}\n"},
+		{"composed", []DumpOption{MinLevel(InfoLevel), OnlyFields("db", "ms"), MaxEntries(2, true), PairedFields()}, "" +
+			"[warning] slow query {db=main, ms=120}\n" +
+			"[error  ] query failed {db=main}\n"},
+	}
+	for _, tt := range tests {
+		if got := string(rec.Dump(tt.opts...)); got != tt.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", tt.name, got, tt.want)
+		}
+	}
+
+	want := "" +
+		"[warning] slow query {db, main, ms, 120, query, q1}\n" +
+		"[error  ] query failed {db, main, error, boom, query, (MISSING)}\n" +
+		"(2 entries below warning level hidden)\n"
+	if got := string(rec.failureDump()); got != want {
+		t.Errorf("got failure dump\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDumpStableFields(t *testing.T) {
+	first, second := NewRecorder(), NewRecorder()
+	NewWithWriter(Config{}, first).With("b", 2, "a", 1).Infow("done", "c", 3)
+	NewWithWriter(Config{}, second).With("c", 3).Infow("done", "a", 1, "b", 2)
+
+	if a, b := string(first.Dump()), string(second.Dump()); a == b {
+		t.Errorf("the default dump sorted the fields: %s", a)
+	}
+	for _, opts := range [][]DumpOption{{SortedFields()}, {SortedFields(), PairedFields()}} {
+		a, b := string(first.Dump(opts...)), string(second.Dump(opts...))
+		if a != b {
+			t.Errorf("got different dumps\n%s\n%s", a, b)
+		}
+	}
+	if got, want := string(first.Dump(SortedFields(), PairedFields())), "[info   ] done {a=1, b=2, c=3}\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	rec := NewRecorder()
+	rec.With("z", 1, "dangling").Log(InfoLevel, "raw")
+	tests := []struct {
+		opts []DumpOption
+		want string
+	}{
+		{nil, "[info   ] raw {z, 1, dangling}\n"},
+		{[]DumpOption{PairedFields()}, "[info   ] raw {z=1, dangling=(MISSING)}\n"},
+		{[]DumpOption{SortedFields(), PairedFields()}, "[info   ] raw {z=1, dangling=(MISSING)}\n"},
+	}
+	for _, tt := range tests {
+		if got := string(rec.Dump(tt.opts...)); got != tt.want {
+			t.Errorf("odd fields: got %q, want %q", got, tt.want)
+		}
+	}
+}
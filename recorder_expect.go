@@ -0,0 +1,144 @@
+package logger
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// Expectation is a builder of log entry matchers.
+// Usage:
+//
+//	rec.Expect().
+//		Level(logger.ErrorLevel).
+//		MessageContains("save failed").
+//		Field("user_id", 42).
+//		WithoutField("password").
+//		Assert(t)
+type Expectation struct {
+	rec      *Recorder
+	matchers []entryMatcher
+	count    int
+}
+
+type entryMatcher struct {
+	desc  string
+	match func(LogEntry) bool
+}
+
+// Expect starts a new expectation over the recorded entries.
+func (rec *Recorder) Expect() *Expectation {
+	return &Expectation{rec: rec, count: -1}
+}
+
+// Level matches entries with the given level.
+func (x *Expectation) Level(level Level) *Expectation {
+	return x.add(fmt.Sprintf("level is %s", level), func(e LogEntry) bool {
+		return e.Level == level
+	})
+}
+
+// MinLevel matches entries with the given level or above,
+// the unknown levels being considered as InfoLevel.
+func (x *Expectation) MinLevel(level Level) *Expectation {
+	return x.add(fmt.Sprintf("level is at least %s", level), func(e LogEntry) bool {
+		return e.Level.orInfo() >= level
+	})
+}
+
+// MessageContains matches entries whose message contains substr.
+func (x *Expectation) MessageContains(substr string) *Expectation {
+	return x.add(fmt.Sprintf("message contains %q", substr), func(e LogEntry) bool {
+		return strings.Contains(e.Message(), substr)
+	})
+}
+
+// MessageRegexp matches entries whose message matches re.
+func (x *Expectation) MessageRegexp(re *regexp.Regexp) *Expectation {
+	return x.add(fmt.Sprintf("message matches %q", re), func(e LogEntry) bool {
+		return re.MatchString(e.Message())
+	})
+}
+
+// Field matches entries with a field whose value is deeply equal to want.
+// If the key is repeated the last value is used.
+func (x *Expectation) Field(key string, want interface{}) *Expectation {
+	return x.add(fmt.Sprintf("field %s=%v", key, want), func(e LogEntry) bool {
+		v, ok := e.Field(key)
+		return ok && reflect.DeepEqual(v, want)
+	})
+}
+
+// FieldMatching matches entries with a field whose value satisfies fn.
+// If the key is repeated the last value is used.
+func (x *Expectation) FieldMatching(key string, fn func(interface{}) bool) *Expectation {
+	return x.add(fmt.Sprintf("field %s matching", key), func(e LogEntry) bool {
+		v, ok := e.Field(key)
+		return ok && fn(v)
+	})
+}
+
+// WithoutField matches entries without the given field.
+func (x *Expectation) WithoutField(key string) *Expectation {
+	return x.add(fmt.Sprintf("without field %s", key), func(e LogEntry) bool {
+		_, ok := e.Field(key)
+		return !ok
+	})
+}
+
+// Count sets the exact number of expected matching entries.
+// By default at least one matching entry is expected.
+func (x *Expectation) Count(n int) *Expectation {
+	x.count = n
+	return x
+}
+
+// Entries returns the recorded entries satisfying every matcher.
+func (x *Expectation) Entries() []LogEntry {
+	var matched []LogEntry
+	for _, e := range x.rec.Entries() {
+		if x.matches(e) {
+			matched = append(matched, e)
+		}
+	}
+	return matched
+}
+
+// Assert fails the test if the matching entries don't meet
+// the expectation, and returns them.
+func (x *Expectation) Assert(t testing.TB) []LogEntry {
+	t.Helper()
+	matched := x.Entries()
+	switch {
+	case x.count < 0 && len(matched) == 0:
+		t.Errorf("no entry matching %s was logged\n%s", x, x.rec.failureDump())
+	case x.count >= 0 && len(matched) != x.count:
+		t.Errorf("expected %d entries matching %s, got %d\n%s", x.count, x, len(matched), x.rec.failureDump())
+	}
+	return matched
+}
+
+// String returns the description of the expectation.
+func (x *Expectation) String() string {
+	descs := make([]string, len(x.matchers))
+	for i, m := range x.matchers {
+		descs[i] = m.desc
+	}
+	return "[" + strings.Join(descs, ", ") + "]"
+}
+
+func (x *Expectation) add(desc string, fn func(LogEntry) bool) *Expectation {
+	x.matchers = append(x.matchers, entryMatcher{desc: desc, match: fn})
+	return x
+}
+
+func (x *Expectation) matches(e LogEntry) bool {
+	for _, m := range x.matchers {
+		if !m.match(e) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,58 @@
+package logger
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestExpect(t *testing.T) {
+	rec := NewRecorder()
+	lg := NewWithWriter(Config{}, rec)
+	lg.Debug("cache warmed")
+	lg.With("user_id", 42).Infof("user %d saved", 42)
+	lg.With("user_id", 7, "user_id", 42).Errorf("save %s: %s", "failed", "timeout")
+	lg.Errorw("save failed", "user_id", 42, "password", "secret")
+	lg.Log(Level(42), "unknown level")
+
+	tests := []struct {
+		name   string
+		x      *Expectation
+		want   int
+		failed string
+	}{
+		{"builder", rec.Expect().Level(ErrorLevel).MessageContains("save failed").Field("user_id", 42).WithoutField("password"), 1, ""},
+		{"formatted message", rec.Expect().MessageContains("user 42 saved"), 1, ""},
+		{"regexp", rec.Expect().MessageRegexp(regexp.MustCompile(`^save (failed|aborted)`)), 2, ""},
+		{"duplicate keys last wins", rec.Expect().Field("user_id", 42), 3, ""},
+		{"field matching", rec.Expect().FieldMatching("user_id", func(v interface{}) bool { return v.(int) > 40 }), 3, ""},
+		{"count", rec.Expect().Level(ErrorLevel).Count(2), 2, ""},
+		{"count zero", rec.Expect().Level(FatalLevel).Count(0), 0, ""},
+		{"min level", rec.Expect().MinLevel(WarningLevel), 2, ""},
+		{"min level of unknown levels", rec.Expect().MinLevel(InfoLevel).MessageContains("unknown"), 1, ""},
+		{"field mismatch", rec.Expect().Field("user_id", int64(42)), 0,
+			"no entry matching [field user_id=42] was logged\n[error  ] save failed: timeout {user_id, 7, user_id, 42}"},
+		{"count mismatch", rec.Expect().Level(ErrorLevel).Count(1), 2,
+			"expected 1 entries matching [level is error], got 2\n"},
+		{"level range", rec.Expect().MinLevel(WarningLevel).Level(InfoLevel), 0,
+			"no entry matching [level is at least warning, level is info] was logged\n"},
+	}
+	for _, tt := range tests {
+		tb := &fakeTB{}
+		if got := tt.x.Assert(tb); len(got) != tt.want {
+			t.Errorf("%s: got %d entries, want %d", tt.name, len(got), tt.want)
+		}
+		switch {
+		case tt.failed == "" && len(tb.errors) > 0:
+			t.Errorf("%s: got failures %q", tt.name, tb.errors)
+		case tt.failed != "" && (len(tb.errors) != 1 || !strings.HasPrefix(tb.errors[0], tt.failed)):
+			t.Errorf("%s: got failures %q, want %q", tt.name, tb.errors, tt.failed)
+		}
+	}
+
+	tb := &fakeTB{}
+	NewRecorder().Expect().Assert(tb)
+	if len(tb.errors) != 1 || tb.errors[0] != "no entry matching [] was logged\n" {
+		t.Errorf("got failures %q without entries", tb.errors)
+	}
+}
@@ -0,0 +1,146 @@
+package logger
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// UpdateGoldenEnv the environment variable making AssertGolden
+// rewrite the golden files when set to "true":
+//
+//	LOGGER_UPDATE_GOLDEN=true go test ./...
+const UpdateGoldenEnv = "LOGGER_UPDATE_GOLDEN"
+
+// goldenConfig holds the AssertGolden settings.
+type goldenConfig struct {
+	replacements []goldenReplacement
+	update       bool
+}
+
+type goldenReplacement struct {
+	re   *regexp.Regexp
+	repl string
+}
+
+// GoldenOption configures the AssertGolden normalization.
+type GoldenOption func(*goldenConfig)
+
+// GoldenReplace replaces every match of re by repl before comparing,
+// e.g. to normalize generated identifiers.
+func GoldenReplace(re *regexp.Regexp, repl string) GoldenOption {
+	return func(c *goldenConfig) {
+		c.replacements = append(c.replacements, goldenReplacement{re: re, repl: repl})
+	}
+}
+
+// GoldenUpdate makes AssertGolden rewrite the golden file, e.g.
+// from a test flag declared by the caller:
+//
+//	var update = flag.Bool("update", false, "update the golden files")
+//	...
+//	rec.AssertGolden(t, "testdata/flow.golden", logger.GoldenUpdateIf(*update))
+func GoldenUpdate() GoldenOption {
+	return GoldenUpdateIf(true)
+}
+
+// GoldenUpdateIf makes AssertGolden rewrite the golden file if update is true.
+func GoldenUpdateIf(update bool) GoldenOption {
+	return func(c *goldenConfig) {
+		c.update = c.update || update
+	}
+}
+
+// AssertGolden compares a normalized dump of the recorded entries with the
+// content of the golden file at path, failing the test with a diff when
+// they don't match. Fields are rendered as key=value pairs sorted by key
+// and timestamps are omitted.
+// The golden file is rewritten instead with the GoldenUpdate option,
+// or when the UpdateGoldenEnv environment variable is "true".
+func (rec *Recorder) AssertGolden(t testing.TB, path string, opts ...GoldenOption) {
+	t.Helper()
+	cfg := goldenConfig{update: os.Getenv(UpdateGoldenEnv) == "true"}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	got := rec.Dump(SortedFields(), PairedFields())
+	for _, r := range cfg.replacements {
+		got = r.re.ReplaceAll(got, []byte(r.repl))
+	}
+
+	if cfg.update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("creating golden file directory: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("writing golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading golden file: %v (set %s=true to create it)", err, UpdateGoldenEnv)
+	}
+	if !bytes.Equal(want, got) {
+		t.Errorf("log entries don't match golden file %s\n%s", path, lineDiff(path, string(want), string(got)))
+	}
+}
+
+// lineDiff returns a unified style diff between two texts.
+func lineDiff(name, a, b string) string {
+	al := strings.SplitAfter(a, "\n")
+	bl := strings.SplitAfter(b, "\n")
+
+	// lcs[i][j] holds the longest common subsequence length of al[i:] and bl[j:].
+	lcs := make([][]int, len(al)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(bl)+1)
+	}
+	for i := len(al) - 1; i >= 0; i-- {
+		for j := len(bl) - 1; j >= 0; j-- {
+			if al[i] == bl[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("--- " + name + "\n+++ got\n")
+	writeLine := func(prefix, line string) {
+		if line == "" {
+			return
+		}
+		sb.WriteString(prefix + strings.TrimSuffix(line, "\n") + "\n")
+	}
+	i, j := 0, 0
+	for i < len(al) && j < len(bl) {
+		switch {
+		case al[i] == bl[j]:
+			writeLine(" ", al[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			writeLine("-", al[i])
+			i++
+		default:
+			writeLine("+", bl[j])
+			j++
+		}
+	}
+	for ; i < len(al); i++ {
+		writeLine("-", al[i])
+	}
+	for ; j < len(bl); j++ {
+		writeLine("+", bl[j])
+	}
+	return sb.String()
+}
@@ -0,0 +1,37 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRecorderAssertGolden(t *testing.T) {
+	rec := NewRecorder()
+	NewWithWriter(Config{}, rec).With("id", "7f0c2a", "b", 2, "a", 1).Info("created")
+	path := filepath.Join(t.TempDir(), "testdata", "created.golden")
+	ids := GoldenReplace(regexp.MustCompile(`id=\w+`), "id=ID")
+
+	rec.AssertGolden(t, path, ids, GoldenUpdate())
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "[info   ] created {a=1, b=2, id=ID}\n"; string(got) != want {
+		t.Errorf("got golden file %q, want %q", got, want)
+	}
+	rec.AssertGolden(t, path, ids)
+}
+
+func TestLineDiff(t *testing.T) {
+	got := lineDiff("want.golden", "a\nb\nc\n", "a\nc\nd\n")
+	want := "--- want.golden\n+++ got\n a\n-b\n c\n+d\n"
+	if got != want {
+		t.Errorf("got diff\n%s\nwant\n%s", got, want)
+	}
+	if strings.Contains(lineDiff("x", "same\n", "same\n"), "\n-") {
+		t.Error("equal texts have removed lines")
+	}
+}
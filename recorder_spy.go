@@ -0,0 +1,72 @@
+package logger
+
+import "time"
+
+// spyWriter records every entry and forwards it to an inner writer.
+type spyWriter struct {
+	rec   *Recorder
+	inner Writer
+}
+
+// NewSpyWriter creates a writer that records every entry like a Recorder
+// and forwards it unchanged to the inner writer.
+// The returned recorder can be used to inspect the entries.
+// Entries are forwarded first, a strict recorder panicking after
+// the inner writer wrote them.
+func NewSpyWriter(inner Writer, opts ...RecorderOption) (*Recorder, Writer) {
+	rec := NewRecorder(opts...)
+	// forwarding adds a frame between the Logger and both writers.
+	return rec, spyWriter{rec: rec, inner: inner}.WithCallerSkip(1)
+}
+
+func (s spyWriter) With(fields ...interface{}) Writer {
+	return spyWriter{
+		rec:   s.rec.With(fields...).(*Recorder),
+		inner: s.inner.With(fields...),
+	}
+}
+
+// WithCallerSkip returns a spy skipping n additional stack
+// frames in both the recorder and the inner writer.
+func (s spyWriter) WithCallerSkip(n int) Writer {
+	if w, ok := s.inner.(interface{ WithCallerSkip(int) Writer }); ok {
+		s.inner = w.WithCallerSkip(n)
+	}
+	s.rec = s.rec.WithCallerSkip(n).(*Recorder)
+	return s
+}
+
+// WithTime returns a spy timestamping the entries with t
+// in both the recorder and the inner writer.
+func (s spyWriter) WithTime(t time.Time) Writer {
+	if w, ok := s.inner.(interface{ WithTime(time.Time) Writer }); ok {
+		s.inner = w.WithTime(t)
+	}
+	s.rec = s.rec.WithTime(t).(*Recorder)
+	return s
+}
+
+func (s spyWriter) Log(level Level, args ...interface{}) {
+	s.inner.Log(level, args...)
+	s.rec.Log(level, args...)
+}
+
+func (s spyWriter) Logf(level Level, str string, args ...interface{}) {
+	s.inner.Logf(level, str, args...)
+	s.rec.Logf(level, str, args...)
+}
+
+func (s spyWriter) Logw(level Level, msg string, keysAndValues ...interface{}) {
+	// not using writew, keeping the same number of frames as Log.
+	if w, ok := s.inner.(WriterW); ok {
+		w.Logw(level, msg, keysAndValues...)
+	} else {
+		s.inner.With(keysAndValues...).Log(level, msg)
+	}
+	s.rec.Logw(level, msg, keysAndValues...)
+}
+
+func (s spyWriter) Sync() {
+	s.rec.Sync()
+	s.inner.Sync()
+}
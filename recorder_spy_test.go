@@ -0,0 +1,72 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSpyWriterZap(t *testing.T) {
+	path, sink := newMemorySink(t)
+	cfg := Config{OutputPaths: []string{path}, DisableInitialFields: true}
+	zw, err := newZapLogger(cfg, 2, cfg.levelVar())
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec, spy := NewSpyWriter(zw, WithCaller())
+	lg := NewWithWriter(cfg, spy)
+
+	ts := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	lg.Info("direct")
+	lg.Infow("direct kv", "k", 1)
+	lg.With("k", 1).Infof("direct %s", "derived")
+	lg.WithTime(ts).Info("timed")
+	appLogger{lg.WithCallerSkip(1)}.info("logger skip")
+	appLogger{lg}.info("helper caller")
+	lg.Sync()
+
+	lines := strings.Split(strings.TrimSpace(sink.String()), "\n")
+	entries := rec.Entries()
+	if len(lines) != 6 || len(entries) != 6 {
+		t.Fatalf("got %d zap entries and %d recorded entries:\n%s", len(lines), len(entries), sink)
+	}
+	for i, line := range lines {
+		var z struct{ Msg, Caller, Ts string }
+		if err := json.Unmarshal([]byte(line), &z); err != nil {
+			t.Fatal(err)
+		}
+		e := entries[i]
+		want := "/recorder_spy_test.go:"
+		if z.Msg == "helper caller" {
+			want = "/app_logger_test.go:"
+		}
+		if e.Message() != z.Msg {
+			t.Errorf("entry %d: got message %q, zap wrote %q", i, e.Message(), z.Msg)
+		}
+		if !strings.Contains(z.Caller, want) || z.Caller != e.Caller.String() {
+			t.Errorf("%s: got zap caller %q and recorded caller %s, want %s", z.Msg, z.Caller, e.Caller, want)
+		}
+		if z.Msg == "timed" && (!strings.HasPrefix(z.Ts, "2024-03-01T12:00:00") || !e.Time.Equal(ts)) {
+			t.Errorf("got zap time %q and recorded time %s, want %s", z.Ts, e.Time, ts)
+		}
+	}
+}
+
+func TestSpyWriterForwardsFirst(t *testing.T) {
+	var msgs []string
+	rec, spy := NewSpyWriter(sprintfWriter{&msgs}, WithStrictSemantics())
+	lg := NewWithWriter(Config{}, spy)
+
+	p := recoverPanic(func() { lg.Panicw("boom", "k", 1) })
+	if _, ok := p.(RecordedPanic); !ok {
+		t.Fatalf("got panic %v, want a RecordedPanic", p)
+	}
+	if fmt.Sprint(msgs) != "[boom]" {
+		t.Errorf("got forwarded messages %q, want the entry written before the panic", msgs)
+	}
+	if !rec.ContainsAt(PanicLevel, "boom") {
+		t.Errorf("got entries:\n%s", rec.Dump())
+	}
+}
@@ -0,0 +1,29 @@
+package logger_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	logger "github.com/Aibier/go-logger"
+)
+
+func TestRecorderSyncCallers(t *testing.T) {
+	rec := logger.NewRecorder()
+	lg := logger.NewWithWriter(logger.Config{}, rec)
+	lg.Sync()
+	lg.With("k", 1).Sync()
+	rec.Sync()
+
+	if got := rec.SyncCount(); got != 3 {
+		t.Errorf("got %d syncs, want 3", got)
+	}
+	callers := rec.SyncCallers()
+	if len(callers) != 3 {
+		t.Fatalf("got %d callers, want 3", len(callers))
+	}
+	for i, f := range callers {
+		if filepath.Base(f.File) != "recorder_sync_test.go" || f.Function != "github.com/Aibier/go-logger_test.TestRecorderSyncCallers" {
+			t.Errorf("caller %d: got %s at %s:%d", i, f.Function, f.File, f.Line)
+		}
+	}
+}
@@ -0,0 +1,452 @@
+package logger
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRecorderMatching(t *testing.T) {
+	rec := NewRecorder()
+	lg := NewWithWriter(Config{}, rec)
+	lg.Warnf("request %s: %v", "r1", "deadline exceeded")
+	lg.Info("connected to ", "db-1")
+	lg.Error("retry ", 3, " failed")
+
+	tests := []struct {
+		name string
+		got  bool
+		want bool
+	}{
+		{"formatted", rec.ContainsMessage("r1: deadline exceeded"), true},
+		{"concatenated", rec.ContainsMessage("connected to db-1"), true},
+		{"missing", rec.ContainsMessage("timeout"), false},
+		{"formatted at level", rec.ContainsAt(WarningLevel, "deadline"), true},
+		{"concatenated at level", rec.ContainsAt(ErrorLevel, "retry 3 failed"), true},
+		{"other level", rec.ContainsAt(ErrorLevel, "deadline"), false},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	re := regexp.MustCompile(`^(request|retry) \S+`)
+	if got := rec.Match(re); len(got) != 2 || got[0].Level != WarningLevel || got[1].Level != ErrorLevel {
+		t.Errorf("Match returned %v", got)
+	}
+	if got := rec.MatchAt(ErrorLevel, re); len(got) != 1 || got[0].Message() != "retry 3 failed" {
+		t.Errorf("MatchAt returned %v", got)
+	}
+	if n := len(rec.Entries()); n != 3 {
+		t.Errorf("matching changed the entries, got %d", n)
+	}
+}
+
+func TestRecorderCapacity(t *testing.T) {
+	rec := NewRecorder(WithCapacity(3))
+	lg := NewWithWriter(Config{}, rec)
+	child := lg.With("child", true)
+	for i := 0; i < 5; i++ {
+		l := lg
+		if i%2 == 1 {
+			l = child
+		}
+		l.Infof("entry %d", i)
+	}
+
+	entries := rec.Entries()
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	for i, e := range entries {
+		if want := fmt.Sprintf("entry %d", i+2); e.Message() != want {
+			t.Errorf("entry %d: got %q, want %q", i, e.Message(), want)
+		}
+	}
+	if _, ok := entries[1].Field("child"); !ok {
+		t.Errorf("the child entry lost its field: %v", entries[1].Fields)
+	}
+	if got := rec.Dropped(); got != 2 {
+		t.Errorf("got %d dropped entries, want 2", got)
+	}
+	if got := child.writer.(*Recorder).Dropped(); got != 2 {
+		t.Errorf("got %d dropped entries from the clone, want 2", got)
+	}
+	if last, _ := rec.LastEntry(); last.Message() != "entry 4" {
+		t.Errorf("got last entry %q", last.Message())
+	}
+}
+
+func TestRecorderUnbounded(t *testing.T) {
+	rec := NewRecorder()
+	lg := NewWithWriter(Config{}, rec)
+	for i := 0; i < 1000; i++ {
+		lg.Info(i)
+	}
+	if n := len(rec.Entries()); n != 1000 || rec.Dropped() != 0 {
+		t.Errorf("got %d entries and %d dropped", n, rec.Dropped())
+	}
+}
+
+func TestRecorderOnRecord(t *testing.T) {
+	rec := NewRecorder()
+	lg := NewWithWriter(Config{}, rec)
+	lg.Info("before")
+
+	var got []string
+	rec.OnRecord(func(e LogEntry) { got = append(got, e.Message()) })
+	lg.With("k", 1).Info("after")
+	if len(got) != 1 || got[0] != "after" {
+		t.Errorf("got %v, want only the entry recorded after the registration", got)
+	}
+}
+
+func TestRecorderNotify(t *testing.T) {
+	rec := NewRecorder()
+	lg := NewWithWriter(Config{}, rec)
+	ch := rec.Notify()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			lg.Errorf("worker %d failed", i)
+		}(i)
+	}
+	wg.Wait()
+	for i := 0; i < 4; i++ {
+		select {
+		case e := <-ch:
+			if e.Level != ErrorLevel {
+				t.Errorf("got a %s entry", e.Level)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("entry %d not notified", i)
+		}
+	}
+
+	for i := 0; i < notifyBufferSize+5; i++ {
+		lg.Info(i)
+	}
+	if got := rec.NotifyDropped(); got != 5 {
+		t.Errorf("got %d dropped notifications, want 5", got)
+	}
+}
+
+func TestRecorderTimestamps(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	clock := NewManualClock(start)
+	rec := NewRecorder()
+	lg := NewWithWriter(Config{Clock: clock}, rec)
+	for i := 0; i < 3; i++ {
+		lg.Info(i)
+		clock.Add(time.Second)
+	}
+
+	entries := rec.Entries()
+	for i, e := range entries {
+		if want := start.Add(time.Duration(i) * time.Second); !e.Time.Equal(want) {
+			t.Errorf("entry %d: got time %v, want %v", i, e.Time, want)
+		}
+		if i > 0 && !e.Time.After(entries[i-1].Time) {
+			t.Errorf("entry %d is not after the previous one", i)
+		}
+	}
+
+	if dump := string(rec.Dump()); strings.Contains(dump, "2024") {
+		t.Errorf("the default dump has timestamps:\n%s", dump)
+	}
+	if dump := string(rec.Dump(DumpTime())); !strings.Contains(dump, "2024-05-01T12:00:02Z") {
+		t.Errorf("the DumpTime dump has no timestamps:\n%s", dump)
+	}
+	js, err := rec.DumpJSON()
+	if err != nil || !strings.Contains(string(js), `"time":"2024-05-01T12:00:01Z"`) {
+		t.Errorf("the json dump has no timestamps, err %v:\n%s", err, js)
+	}
+
+	rec.SetClock(NewManualClock(start.Add(time.Hour)))
+	lg.Info("later")
+	if last, _ := rec.LastEntry(); !last.Time.Equal(start.Add(time.Hour)) {
+		t.Errorf("SetClock ignored, got time %v", last.Time)
+	}
+}
+
+func TestRecorderSystemClock(t *testing.T) {
+	rec := NewRecorder()
+	before := time.Now()
+	NewWithWriter(Config{}, rec).Info("now")
+	if e, _ := rec.LastEntry(); e.Time.Before(before) || e.Time.After(time.Now()) {
+		t.Errorf("got time %v", e.Time)
+	}
+}
+
+func TestRecorderWithCaller(t *testing.T) {
+	rec := NewRecorder(WithCaller())
+	lg := NewWithWriter(Config{}, rec)
+	lg.Info("direct")
+	lg.Infow("direct kv", "k", 1)
+	lg.With("k", 1).Infof("direct %s", "derived")
+	appLogger{lg}.info("helper caller")
+	appLogger{lg.WithCallerSkip(1)}.info("logger skip")
+	appLogger{lg.WithCallerSkip(1).With("k", 1)}.info("derived skip")
+	appLogger{NewWithWriter(Config{CallerSkip: 1}, rec)}.info("config skip")
+
+	entries := rec.Entries()
+	if len(entries) != 7 {
+		t.Fatalf("got %d entries", len(entries))
+	}
+	for _, e := range entries {
+		want := "/recorder_test.go:"
+		if e.Message() == "helper caller" {
+			want = "/app_logger_test.go:"
+		}
+		if !e.Caller.Defined || !strings.Contains(e.Caller.String(), want) {
+			t.Errorf("%s: got caller %s, want %s", e.Message(), e.Caller, want)
+		}
+	}
+	if fn := entries[0].Caller.Function; !strings.HasSuffix(fn, ".TestRecorderWithCaller") {
+		t.Errorf("got caller function %q", fn)
+	}
+
+	rec = NewRecorder()
+	NewWithWriter(Config{}, rec).Info("no caller")
+	if c := rec.Entries()[0].Caller; c.Defined {
+		t.Errorf("got caller %s without WithCaller", c)
+	}
+}
+
+func TestRecorderMarkConcurrent(t *testing.T) {
+	rec := NewRecorder()
+	lg := NewWithWriter(Config{}, rec)
+	lg.Info("phase 1")
+
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			<-start
+			for i := 0; i < 200; i++ {
+				lg.Debugf("worker %d entry %d", w, i)
+			}
+		}(w)
+	}
+	close(start)
+	var marks []Mark
+	for i := 0; i < 10; i++ {
+		marks = append(marks, rec.Mark())
+		lg.Errorf("phase 2 step %d", i)
+	}
+	wg.Wait()
+
+	all := rec.Entries()
+	if len(all) != 1+4*200+10 {
+		t.Fatalf("got %d entries", len(all))
+	}
+	for i, m := range marks {
+		since := rec.EntriesSince(m)
+		if rec.TruncatedSince(m) {
+			t.Errorf("mark %d: got truncated without capacity", i)
+		}
+		if len(since) == 0 || since[0].Message() != fmt.Sprintf("phase 2 step %d", i) {
+			t.Fatalf("mark %d: got first entry %v, want the one logged after the mark", i, since[:1])
+		}
+		if fmt.Sprint(since) != fmt.Sprint(all[len(all)-len(since):]) {
+			t.Errorf("mark %d: the entries since are not the last entries", i)
+		}
+		if n := rec.CountSince(m, ErrorLevel); n != 10-i {
+			t.Errorf("mark %d: got %d errors since, want %d", i, n, 10-i)
+		}
+	}
+}
+
+func TestRecorderMarkEvicted(t *testing.T) {
+	rec := NewRecorder(WithCapacity(5))
+	lg := NewWithWriter(Config{}, rec)
+	lg.Info("before")
+	evicted := rec.Mark()
+	for i := 0; i < 7; i++ {
+		lg.Infof("entry %d", i)
+	}
+	kept := rec.Mark()
+	lg.Info("after")
+
+	since := rec.EntriesSince(evicted)
+	if !rec.TruncatedSince(evicted) || len(since) != 5 || since[0].Message() != "entry 3" {
+		t.Errorf("got truncated %v and entries %v, want the 5 retained ones", rec.TruncatedSince(evicted), since)
+	}
+	if since := rec.EntriesSince(kept); rec.TruncatedSince(kept) || len(since) != 1 || since[0].Message() != "after" {
+		t.Errorf("got truncated %v and entries %v", rec.TruncatedSince(kept), since)
+	}
+
+	rec.Reset()
+	if since := rec.EntriesSince(kept); !rec.TruncatedSince(kept) || len(since) != 0 {
+		t.Errorf("got truncated %v and entries %v after Reset", rec.TruncatedSince(kept), since)
+	}
+}
+
+func TestEntriesByGoroutineLabel(t *testing.T) {
+	rec := NewRecorder()
+	lg := NewWithWriter(Config{}, rec)
+	lg.Info("unlabelled")
+
+	var wg sync.WaitGroup
+	for w := 0; w < 10; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			wl := lg.WithGoroutineLabel(fmt.Sprintf("worker-%d", w))
+			for i := 0; i <= w; i++ {
+				wl.Infow("job done", "job", i)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	groups := rec.EntriesByField(GoroutineLabelKey)
+	if len(groups) != 10 {
+		t.Fatalf("got %d groups", len(groups))
+	}
+	for w := 0; w < 10; w++ {
+		group := groups[fmt.Sprintf("worker-%d", w)]
+		if len(group) != w+1 {
+			t.Errorf("worker %d: got %d entries, want %d", w, len(group), w+1)
+		}
+		for i, e := range group {
+			if job, _ := e.Field("job"); job != i {
+				t.Errorf("worker %d: got job %v at %d, want the worker order", w, job, i)
+			}
+		}
+	}
+}
+
+func TestRecorderMaxBytes(t *testing.T) {
+	const maxBytes = 10000
+	rec := NewRecorder(WithMaxBytes(maxBytes))
+	lg := NewWithWriter(Config{}, rec)
+	big := strings.Repeat("x", 1000)
+
+	var sizes []int
+	for i := 0; i < 50; i++ {
+		lg.Info(big, i)
+		sizes = append(sizes, rec.ApproxBytes())
+	}
+	if sizes[0] < len(big) || sizes[1] <= sizes[0] {
+		t.Errorf("got sizes %v, want them growing with the entries", sizes[:2])
+	}
+	if n := rec.ApproxBytes(); n > maxBytes+2*entrySize(LogEntry{Args: []interface{}{big}}) {
+		t.Errorf("got %d bytes, want the cap of %d enforced", n, maxBytes)
+	}
+
+	checkNotice := func() {
+		t.Helper()
+		entries := rec.Entries()
+		var notices int
+		for _, e := range entries {
+			if strings.HasPrefix(e.Message(), "recorder memory cap of 10000 bytes reached") {
+				notices++
+			}
+		}
+		if notices != 1 || entries[0].Level != WarningLevel || !strings.HasPrefix(entries[0].Message(), "recorder memory cap") {
+			t.Errorf("got %d notices, want exactly one first entry:\n%s", notices, rec.Dump(OnlyFields()))
+		}
+		if last := entries[len(entries)-1].Message(); !strings.HasSuffix(last, "49") {
+			t.Errorf("got last entry %q, want the newest one retained", last)
+		}
+		if len(entries) > 10 {
+			t.Errorf("got %d entries, want the oldest ones dropped", len(entries))
+		}
+	}
+	checkNotice()
+
+	rec.Reset()
+	if n := rec.ApproxBytes(); n != 0 {
+		t.Errorf("got %d bytes after Reset", n)
+	}
+	for i := 0; i < 50; i++ {
+		lg.Info(big, i)
+	}
+	checkNotice()
+}
+
+func TestRecorderLastEntryTakeAll(t *testing.T) {
+	rec := NewRecorder()
+	if _, ok := rec.LastEntry(); ok {
+		t.Error("got a last entry from an empty recorder")
+	}
+	if got := rec.TakeAll(); len(got) != 0 {
+		t.Errorf("got %d entries from an empty recorder", len(got))
+	}
+
+	lg := NewWithWriter(Config{}, rec)
+	child := lg.With("child", true)
+	lg.Info("first")
+	child.Warn("second")
+	lg.Sync()
+	clone := child.writer.(*Recorder)
+	if e, ok := clone.LastEntry(); !ok || e.Message() != "second" || e.Level != WarningLevel {
+		t.Errorf("got last entry %v, %v from the clone", e, ok)
+	}
+
+	taken := clone.TakeAll()
+	if len(taken) != 2 || taken[0].Message() != "first" || taken[1].Message() != "second" {
+		t.Fatalf("got taken entries %v", taken)
+	}
+	if _, ok := taken[1].Field("child"); !ok {
+		t.Errorf("the taken child entry lost its field: %v", taken[1].Fields)
+	}
+	if n := len(rec.Entries()); n != 0 {
+		t.Errorf("got %d entries after TakeAll", n)
+	}
+	if _, ok := rec.LastEntry(); ok {
+		t.Error("got a last entry after TakeAll")
+	}
+	if !rec.SyncCalled() {
+		t.Error("TakeAll reset the sync state")
+	}
+
+	child.Error("third")
+	if e, ok := rec.LastEntry(); !ok || e.Message() != "third" {
+		t.Errorf("got last entry %v, %v after TakeAll", e, ok)
+	}
+	if got := rec.TakeAll(); len(got) != 1 || got[0].Message() != "third" {
+		t.Errorf("got taken entries %v, want only the new one", got)
+	}
+}
+
+func TestRecorderReset(t *testing.T) {
+	rec := NewRecorder(WithCapacity(1))
+	lg := NewWithWriter(Config{}, rec)
+	child := lg.With("case", 1)
+	child.Info("first")
+	child.Info("second")
+	lg.Sync()
+
+	child.writer.(*Recorder).Reset()
+	if n := len(rec.Entries()); n != 0 {
+		t.Errorf("got %d entries after Reset", n)
+	}
+	if rec.SyncCalled() || rec.Dropped() != 0 {
+		t.Errorf("got sync called %v and %d dropped after Reset", rec.SyncCalled(), rec.Dropped())
+	}
+	child.Info("third")
+	if e, ok := rec.LastEntry(); !ok || e.Message() != "third" || e.FieldMap()["case"] != 1 {
+		t.Errorf("got entry %v, %v, want the clone fields kept", e, ok)
+	}
+
+	tb := &fakeTB{}
+	rec.ResetOnCleanup(tb)
+	if n := len(rec.Entries()); n != 1 {
+		t.Fatalf("got %d entries before the cleanup", n)
+	}
+	tb.cleanup()
+	if n := len(rec.Entries()); n != 0 {
+		t.Errorf("got %d entries after the cleanup", n)
+	}
+}
@@ -0,0 +1,49 @@
+package logger
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+)
+
+// PanicMessage is the message of the entries logged by LogPanic.
+const PanicMessage = "panic recovered"
+
+// LogPanic logs a recovered panic value as an error entry with the
+// stack trace and the given key/value pairs, e.g. in a recovery
+// middleware, see RecoverAndLog.
+func (l Logger) LogPanic(recovered interface{}, keysAndValues ...interface{}) {
+	l.logw(ErrorLevel, PanicMessage, panicFields(recovered, keysAndValues))
+}
+
+// RecoverAndLog recovers a panic and logs it, see LogPanic, then
+// calls the handlers with the panic value. It must be deferred directly:
+//
+//	go func() {
+//		defer lg.RecoverAndLog()
+//		...
+//	}()
+//
+// The http.ErrAbortHandler panics, aborting a response, are not logged
+// and propagate.
+func (l Logger) RecoverAndLog(handlers ...func(recovered interface{})) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	if r == http.ErrAbortHandler {
+		panic(r)
+	}
+	l.logw(ErrorLevel, PanicMessage, panicFields(r, nil))
+	for _, h := range handlers {
+		h(r)
+	}
+}
+
+// panicFields returns the key/value pairs of a recovered panic.
+func panicFields(recovered interface{}, keysAndValues []interface{}) []interface{} {
+	return append([]interface{}{
+		"panic", fmt.Sprint(recovered),
+		"stack", string(debug.Stack()),
+	}, keysAndValues...)
+}
@@ -0,0 +1,57 @@
+package logger
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRecoverAndLog(t *testing.T) {
+	rec := NewRecorder()
+	lg := NewWithWriter(Config{}, rec).With("worker", 1)
+
+	var recovered interface{}
+	func() {
+		defer lg.RecoverAndLog(func(r interface{}) { recovered = r })
+		panic("boom")
+	}()
+	if recovered != "boom" {
+		t.Errorf("got handler value %#v", recovered)
+	}
+	e, _ := rec.LastEntry()
+	if e.Level != ErrorLevel || e.Message() != PanicMessage {
+		t.Fatalf("got entry %v", e)
+	}
+	if v, _ := e.Field("panic"); v != "boom" {
+		t.Errorf("got panic field %v", v)
+	}
+	if v, _ := e.Field("stack"); !strings.Contains(v.(string), "TestRecoverAndLog") {
+		t.Errorf("the stack is missing the panicking function:\n%v", v)
+	}
+	if _, ok := e.Field("worker"); !ok {
+		t.Errorf("the logger fields are missing: %v", e.Fields)
+	}
+
+	v := recoverPanic(func() {
+		defer lg.RecoverAndLog()
+		panic(http.ErrAbortHandler)
+	})
+	if v != http.ErrAbortHandler || len(rec.Entries()) != 1 {
+		t.Errorf("got panic value %#v, want http.ErrAbortHandler propagated without an entry", v)
+	}
+
+	func() {
+		defer lg.RecoverAndLog(func(interface{}) { t.Error("handler called without a panic") })
+	}()
+}
+
+func TestLogPanic(t *testing.T) {
+	rec := NewRecorder()
+	NewWithWriter(Config{}, rec).LogPanic("boom", "route", "/users/:id")
+	e, _ := rec.LastEntry()
+	for k, want := range map[string]interface{}{"panic": "boom", "route": "/users/:id"} {
+		if v, _ := e.Field(k); v != want {
+			t.Errorf("%s: got %v, want %v", k, v, want)
+		}
+	}
+}
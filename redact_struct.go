@@ -0,0 +1,196 @@
+package logger
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// structMaskValue replaces the values of the struct fields tagged log:"mask".
+const structMaskValue = "***"
+
+// Redacted returns a representation of v safe for logging, honoring the
+// log struct tags: fields tagged log:"-" are omitted and fields tagged
+// log:"mask" are replaced by "***".
+// Structs containing tagged fields, directly or through nested structs,
+// pointers, slices, arrays or maps, are converted to maps keyed by their
+// json name. Other values are returned unchanged. Cycles are replaced
+// by "<cycle>".
+// Usage: lg.With("request", logger.Redacted(req))
+func Redacted(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	if !redactInfo(rv.Type()).redact {
+		return v
+	}
+	return redactValue(rv, map[uintptr]bool{})
+}
+
+// structField holds the log tag analysis of a struct field.
+type structField struct {
+	index    int
+	name     string
+	omit     bool
+	mask     bool
+	embedded bool
+}
+
+// typeRedaction holds the log tag analysis of a type.
+type typeRedaction struct {
+	redact bool
+	fields []structField
+}
+
+var redactCache sync.Map // map[reflect.Type]*typeRedaction
+
+func redactInfo(t reflect.Type) *typeRedaction {
+	if info, ok := redactCache.Load(t); ok {
+		return info.(*typeRedaction)
+	}
+	info := analyzeType(t, map[reflect.Type]bool{})
+	redactCache.Store(t, info)
+	return info
+}
+
+func analyzeType(t reflect.Type, visiting map[reflect.Type]bool) *typeRedaction {
+	if info, ok := redactCache.Load(t); ok {
+		return info.(*typeRedaction)
+	}
+	info := &typeRedaction{}
+	if visiting[t] {
+		return info
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		info.redact = analyzeType(t.Elem(), visiting).redact
+	case reflect.Map:
+		info.redact = analyzeType(t.Elem(), visiting).redact
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" && !f.Anonymous {
+				continue
+			}
+			sf := structField{
+				index:    i,
+				name:     f.Name,
+				embedded: f.Anonymous,
+			}
+			if name := strings.Split(f.Tag.Get("json"), ",")[0]; name != "" && name != "-" {
+				sf.name = name
+				sf.embedded = false
+			}
+			switch f.Tag.Get("log") {
+			case "-":
+				sf.omit = true
+				info.redact = true
+			case "mask":
+				sf.mask = true
+				info.redact = true
+			}
+			if analyzeType(f.Type, visiting).redact {
+				info.redact = true
+			}
+			info.fields = append(info.fields, sf)
+		}
+	}
+	return info
+}
+
+func redactValue(v reflect.Value, seen map[uintptr]bool) interface{} {
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return redactValue(v.Elem(), seen)
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		if !redactInfo(v.Type()).redact {
+			return v.Interface()
+		}
+		ptr := v.Pointer()
+		if seen[ptr] {
+			return "<cycle>"
+		}
+		seen[ptr] = true
+		defer delete(seen, ptr)
+		return redactValue(v.Elem(), seen)
+	case reflect.Slice, reflect.Array:
+		if !redactInfo(v.Type()).redact {
+			return v.Interface()
+		}
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		out := make([]interface{}, v.Len())
+		for i := range out {
+			out[i] = redactValue(v.Index(i), seen)
+		}
+		return out
+	case reflect.Map:
+		if !redactInfo(v.Type()).redact {
+			return v.Interface()
+		}
+		if v.IsNil() {
+			return nil
+		}
+		out := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out[fieldKey(iter.Key().Interface())] = redactValue(iter.Value(), seen)
+		}
+		return out
+	case reflect.Struct:
+		info := redactInfo(v.Type())
+		if !info.redact {
+			return v.Interface()
+		}
+		out := make(map[string]interface{}, len(info.fields))
+		redactStruct(v, info, out, seen)
+		return out
+	}
+	if !v.CanInterface() {
+		return nil
+	}
+	return v.Interface()
+}
+
+// redactStruct adds the struct fields to out,
+// flattening the embedded structs.
+func redactStruct(v reflect.Value, info *typeRedaction, out map[string]interface{}, seen map[uintptr]bool) {
+	for _, f := range info.fields {
+		if f.omit {
+			continue
+		}
+		if f.mask {
+			out[f.name] = structMaskValue
+			continue
+		}
+		fv := v.Field(f.index)
+		if f.embedded {
+			ev := fv
+			if ev.Kind() == reflect.Ptr {
+				if ev.IsNil() {
+					continue
+				}
+				ev = ev.Elem()
+			}
+			if ev.Kind() == reflect.Struct {
+				redactStruct(ev, redactInfo(ev.Type()), out, seen)
+				continue
+			}
+		}
+		if !fv.CanInterface() {
+			continue
+		}
+		out[f.name] = redactValue(fv, seen)
+	}
+}
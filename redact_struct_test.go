@@ -0,0 +1,102 @@
+package logger
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type redactUser struct {
+	Name     string `json:"name"`
+	Password string `log:"-"`
+	Token    string `json:"token" log:"mask"`
+}
+
+type redactBase struct {
+	ID     int
+	Secret string `json:"secret" log:"mask"`
+}
+
+type redactRequest struct {
+	redactBase
+	User    *redactUser
+	Users   []redactUser `json:"users"`
+	ByName  map[string]redactUser
+	Plain   struct{ N int }
+	private string
+}
+
+type redactNode struct {
+	Name   string
+	Secret string `log:"mask"`
+	Next   *redactNode
+}
+
+func TestRedacted(t *testing.T) {
+	user := redactUser{Name: "bob", Password: "hunter2", Token: "tok"}
+	req := redactRequest{
+		redactBase: redactBase{ID: 7, Secret: "s"},
+		User:       &user,
+		Users:      []redactUser{user},
+		ByName:     map[string]redactUser{"bob": user},
+		Plain:      struct{ N int }{1},
+		private:    "p",
+	}
+	redactedUser := map[string]interface{}{"name": "bob", "token": "***"}
+	want := map[string]interface{}{
+		"ID":     7,
+		"secret": "***",
+		"User":   redactedUser,
+		"users":  []interface{}{redactedUser},
+		"ByName": map[string]interface{}{"bob": redactedUser},
+		"Plain":  struct{ N int }{1},
+	}
+	if got := Redacted(req); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v\nwant %#v", got, want)
+	}
+	if got := Redacted(&req); !reflect.DeepEqual(got, want) {
+		t.Errorf("pointer: got %#v\nwant %#v", got, want)
+	}
+
+	b, err := json.Marshal(Redacted(req))
+	if err != nil {
+		t.Fatal(err)
+	}
+	const wantJSON = `{"ByName":{"bob":{"name":"bob","token":"***"}},"ID":7,"Plain":{"N":1},` +
+		`"User":{"name":"bob","token":"***"},"secret":"***","users":[{"name":"bob","token":"***"}]}`
+	if string(b) != wantJSON {
+		t.Errorf("got JSON %s", b)
+	}
+
+	plain := struct{ A, B int }{1, 2}
+	if got := Redacted(plain); got != plain {
+		t.Errorf("got %#v, want the untagged struct unchanged", got)
+	}
+	if got := Redacted(nil); got != nil {
+		t.Errorf("got %#v for nil", got)
+	}
+	var nilUser *redactUser
+	if got := Redacted(nilUser); got != nil {
+		t.Errorf("got %#v for a nil pointer", got)
+	}
+}
+
+func TestRedactedCycle(t *testing.T) {
+	a := &redactNode{Name: "a", Secret: "x"}
+	b := &redactNode{Name: "b", Secret: "y", Next: a}
+	a.Next = b
+
+	want := map[string]interface{}{
+		"Name": "a", "Secret": "***",
+		"Next": map[string]interface{}{"Name": "b", "Secret": "***", "Next": "<cycle>"},
+	}
+	if got := Redacted(a); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v\nwant %#v", got, want)
+	}
+
+	rec := NewRecorder()
+	NewWithWriter(Config{}, rec).Infow("node", "node", Redacted(a))
+	if e, _ := rec.LastEntry(); !reflect.DeepEqual(e.FieldMap()["node"], want) {
+		t.Errorf("got logged field %#v", e.FieldMap()["node"])
+	}
+}
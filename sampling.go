@@ -0,0 +1,105 @@
+package logger
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// SamplingConfig limits the number of identical entries, with the
+// same level and message, written per tick: the first Initial ones
+// are written, then one every Thereafter, the others are dropped.
+type SamplingConfig struct {
+	// Initial the number of entries written per tick.
+	// Initial and Thereafter cannot both be 0.
+	Initial int
+
+	// Thereafter once Initial is reached, one entry out of Thereafter
+	// is written during the rest of the tick, none when 0.
+	Thereafter int
+
+	// Tick the sampling period, one second by default.
+	Tick time.Duration
+
+	// MaxLevel the highest sampled level, WarningLevel when nil:
+	// the entries of higher levels are always written.
+	MaxLevel *Level
+
+	// OnDropped is called for every entry dropped by the sampling.
+	OnDropped func(Level)
+}
+
+// samplingOptions returns the zap options sampling the entries.
+func (c Config) samplingOptions() []zap.Option {
+	s := c.Sampling
+	if s == nil {
+		return nil
+	}
+	tick := s.Tick
+	if tick <= 0 {
+		tick = time.Second
+	}
+	var opts []zapcore.SamplerOption
+	if s.OnDropped != nil {
+		onDropped := s.OnDropped
+		opts = append(opts, zapcore.SamplerHook(func(ent zapcore.Entry, dec zapcore.SamplingDecision) {
+			if dec&zapcore.LogDropped != 0 {
+				onDropped(levelFromZap(ent.Level))
+			}
+		}))
+	}
+	max := zapLevel(WarningLevel)
+	if s.MaxLevel != nil {
+		max = zapLevel(*s.MaxLevel)
+	}
+	return []zap.Option{zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return levelSampler{
+			Core:    core,
+			sampled: zapcore.NewSamplerWithOptions(core, tick, s.Initial, s.Thereafter, opts...),
+			max:     max,
+		}
+	})}
+}
+
+// levelSampler samples the entries up to the max level.
+type levelSampler struct {
+	zapcore.Core
+	sampled zapcore.Core
+	max     zapcore.Level
+}
+
+func (s levelSampler) With(fields []zapcore.Field) zapcore.Core {
+	return levelSampler{
+		Core:    s.Core.With(fields),
+		sampled: s.sampled.With(fields),
+		max:     s.max,
+	}
+}
+
+func (s levelSampler) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if ent.Level > s.max {
+		return s.Core.Check(ent, ce)
+	}
+	return s.sampled.Check(ent, ce)
+}
+
+// levelFromZap returns the Level matching a zap level.
+func levelFromZap(l zapcore.Level) Level {
+	switch {
+	case l <= zapcore.DebugLevel:
+		return DebugLevel
+	case l == zapcore.InfoLevel:
+		return InfoLevel
+	case l == zapcore.WarnLevel:
+		return WarningLevel
+	case l == zapcore.ErrorLevel:
+		return ErrorLevel
+	case l == zapcore.DPanicLevel:
+		return DPanicLevel
+	case l == zapcore.FatalLevel:
+		return FatalLevel
+	default:
+		return PanicLevel
+	}
+}
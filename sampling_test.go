@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSampling(t *testing.T) {
+	debugLevel := DebugLevel
+	tests := []struct {
+		name          string
+		maxLevel      *Level
+		warnings      int
+		errors        int
+		dropped       int
+		droppedLevels Level
+	}{
+		{"default max level", nil, 11, 100, 89, WarningLevel},
+		{"debug max level", &debugLevel, 100, 100, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, sink := newMemorySink(t)
+			var dropped int
+			lg, err := New(Config{
+				OutputPaths:          []string{path},
+				DisableInitialFields: true,
+				Sampling: &SamplingConfig{
+					Initial:    10,
+					Thereafter: 50,
+					Tick:       time.Minute,
+					MaxLevel:   tt.maxLevel,
+					OnDropped: func(level Level) {
+						if level != tt.droppedLevels {
+							t.Errorf("got dropped %s entry", level)
+						}
+						dropped++
+					},
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < 100; i++ {
+				lg.Warn("disk almost full")
+				lg.Error("disk full")
+			}
+			lg.Sync()
+
+			out := sink.String()
+			if n := strings.Count(out, "disk almost full"); n != tt.warnings {
+				t.Errorf("got %d warnings, want %d", n, tt.warnings)
+			}
+			if n := strings.Count(out, "disk full"); n != tt.errors {
+				t.Errorf("got %d errors, want %d", n, tt.errors)
+			}
+			if dropped != tt.dropped {
+				t.Errorf("got %d dropped entries, want %d", dropped, tt.dropped)
+			}
+		})
+	}
+}
+
+func TestSamplingValidate(t *testing.T) {
+	invalid := Level(42)
+	tests := []struct {
+		name     string
+		sampling SamplingConfig
+		want     string
+	}{
+		{"drop everything", SamplingConfig{}, "both 0"},
+		{"negative", SamplingConfig{Initial: -1, Thereafter: 1}, "negative sampling"},
+		{"unknown max level", SamplingConfig{Initial: 1, MaxLevel: &invalid}, "unknown sampling max level 42"},
+	}
+	for _, tt := range tests {
+		err := Config{Sampling: &tt.sampling}.Validate()
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: got error %v, want %q", tt.name, err, tt.want)
+		}
+	}
+	if err := (Config{Sampling: &SamplingConfig{Initial: 1}}).Validate(); err != nil {
+		t.Errorf("got error %v", err)
+	}
+}
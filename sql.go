@@ -0,0 +1,293 @@
+package logger
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// SQLOptions configures the query logging of a LoggedDB.
+type SQLOptions struct {
+	// SlowThreshold the duration above which successful
+	// queries are logged as warnings. Disabled when zero.
+	SlowThreshold time.Duration
+
+	// LogArgs when true the query arguments are logged.
+	LogArgs bool
+
+	// Masker when set masquerades the literal values of the
+	// queries and the logged arguments.
+	Masker *Masker
+}
+
+// LoggedDB wraps a *sql.DB logging every query with its duration,
+// rows affected and error. Queries are logged using the logger of the
+// context, see FromContextLogger, or the one of the LoggedDB if none:
+// as debug on success, as warnings when slow and as errors on failure.
+type LoggedDB struct {
+	*sql.DB
+	log sqlLogger
+}
+
+// WrapDB returns db logging its queries with l.
+func WrapDB(db *sql.DB, l Logger, opts SQLOptions) *LoggedDB {
+	return &LoggedDB{DB: db, log: sqlLogger{l: l, opts: opts}}
+}
+
+// Query executes a query that returns rows.
+func (db *LoggedDB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return db.QueryContext(context.Background(), query, args...)
+}
+
+// QueryRow executes a query that is expected to return at most one row.
+func (db *LoggedDB) QueryRow(query string, args ...interface{}) *sql.Row {
+	return db.QueryRowContext(context.Background(), query, args...)
+}
+
+// Exec executes a query without returning any rows.
+func (db *LoggedDB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return db.ExecContext(context.Background(), query, args...)
+}
+
+// Prepare creates a prepared statement whose executions are logged.
+func (db *LoggedDB) Prepare(query string) (*LoggedStmt, error) {
+	return db.PrepareContext(context.Background(), query)
+}
+
+// Begin starts a transaction whose queries are logged.
+func (db *LoggedDB) Begin() (*LoggedTx, error) {
+	return db.BeginTx(context.Background(), nil)
+}
+
+// QueryContext executes a query that returns rows.
+func (db *LoggedDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := db.DB.QueryContext(ctx, query, args...)
+	db.log.log(ctx, "query", query, args, start, nil, err)
+	return rows, err
+}
+
+// QueryRowContext executes a query that is expected to return at most one row.
+func (db *LoggedDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	start := time.Now()
+	row := db.DB.QueryRowContext(ctx, query, args...)
+	db.log.log(ctx, "query", query, args, start, nil, row.Err())
+	return row
+}
+
+// ExecContext executes a query without returning any rows.
+func (db *LoggedDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	start := time.Now()
+	res, err := db.DB.ExecContext(ctx, query, args...)
+	db.log.log(ctx, "exec", query, args, start, res, err)
+	return res, err
+}
+
+// PrepareContext creates a prepared statement whose executions are logged.
+func (db *LoggedDB) PrepareContext(ctx context.Context, query string) (*LoggedStmt, error) {
+	start := time.Now()
+	stmt, err := db.DB.PrepareContext(ctx, query)
+	db.log.log(ctx, "prepare", query, nil, start, nil, err)
+	if err != nil {
+		return nil, err
+	}
+	return &LoggedStmt{Stmt: stmt, query: query, log: db.log}, nil
+}
+
+// BeginTx starts a transaction whose queries are logged.
+func (db *LoggedDB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*LoggedTx, error) {
+	start := time.Now()
+	tx, err := db.DB.BeginTx(ctx, opts)
+	db.log.log(ctx, "begin", "", nil, start, nil, err)
+	if err != nil {
+		return nil, err
+	}
+	return &LoggedTx{Tx: tx, ctx: ctx, log: db.log}, nil
+}
+
+// LoggedTx wraps a *sql.Tx logging its queries, commit and rollback.
+// The statements it prepares, or adapts with Stmt, are logged too.
+type LoggedTx struct {
+	*sql.Tx
+	ctx context.Context
+	log sqlLogger
+}
+
+// Query executes a query that returns rows.
+func (tx *LoggedTx) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return tx.QueryContext(tx.ctx, query, args...)
+}
+
+// QueryRow executes a query that is expected to return at most one row.
+func (tx *LoggedTx) QueryRow(query string, args ...interface{}) *sql.Row {
+	return tx.QueryRowContext(tx.ctx, query, args...)
+}
+
+// Exec executes a query without returning any rows.
+func (tx *LoggedTx) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return tx.ExecContext(tx.ctx, query, args...)
+}
+
+// QueryContext executes a query that returns rows.
+func (tx *LoggedTx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := tx.Tx.QueryContext(ctx, query, args...)
+	tx.log.log(ctx, "query", query, args, start, nil, err)
+	return rows, err
+}
+
+// QueryRowContext executes a query that is expected to return at most one row.
+func (tx *LoggedTx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	start := time.Now()
+	row := tx.Tx.QueryRowContext(ctx, query, args...)
+	tx.log.log(ctx, "query", query, args, start, nil, row.Err())
+	return row
+}
+
+// ExecContext executes a query without returning any rows.
+func (tx *LoggedTx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	start := time.Now()
+	res, err := tx.Tx.ExecContext(ctx, query, args...)
+	tx.log.log(ctx, "exec", query, args, start, res, err)
+	return res, err
+}
+
+// Prepare creates a prepared statement for use within the
+// transaction whose executions are logged.
+func (tx *LoggedTx) Prepare(query string) (*LoggedStmt, error) {
+	return tx.PrepareContext(tx.ctx, query)
+}
+
+// PrepareContext creates a prepared statement for use within the
+// transaction whose executions are logged.
+func (tx *LoggedTx) PrepareContext(ctx context.Context, query string) (*LoggedStmt, error) {
+	start := time.Now()
+	stmt, err := tx.Tx.PrepareContext(ctx, query)
+	tx.log.log(ctx, "prepare", query, nil, start, nil, err)
+	if err != nil {
+		return nil, err
+	}
+	return &LoggedStmt{Stmt: stmt, query: query, log: tx.log}, nil
+}
+
+// Stmt returns a transaction-specific prepared statement
+// from an existing statement, whose executions are logged.
+func (tx *LoggedTx) Stmt(stmt *LoggedStmt) *LoggedStmt {
+	return tx.StmtContext(tx.ctx, stmt)
+}
+
+// StmtContext returns a transaction-specific prepared statement
+// from an existing statement, whose executions are logged.
+func (tx *LoggedTx) StmtContext(ctx context.Context, stmt *LoggedStmt) *LoggedStmt {
+	return &LoggedStmt{Stmt: tx.Tx.StmtContext(ctx, stmt.Stmt), query: stmt.query, log: tx.log}
+}
+
+// Commit commits the transaction.
+func (tx *LoggedTx) Commit() error {
+	start := time.Now()
+	err := tx.Tx.Commit()
+	tx.log.log(tx.ctx, "commit", "", nil, start, nil, err)
+	return err
+}
+
+// Rollback aborts the transaction. Rolling back a committed or rolled
+// back transaction, e.g. deferred after Commit, returns sql.ErrTxDone
+// and logs nothing.
+func (tx *LoggedTx) Rollback() error {
+	start := time.Now()
+	err := tx.Tx.Rollback()
+	if errors.Is(err, sql.ErrTxDone) {
+		return err
+	}
+	tx.log.log(tx.ctx, "rollback", "", nil, start, nil, err)
+	return err
+}
+
+// LoggedStmt wraps a *sql.Stmt logging its executions.
+type LoggedStmt struct {
+	*sql.Stmt
+	query string
+	log   sqlLogger
+}
+
+// Query executes the prepared query statement.
+func (s *LoggedStmt) Query(args ...interface{}) (*sql.Rows, error) {
+	return s.QueryContext(context.Background(), args...)
+}
+
+// Exec executes the prepared statement.
+func (s *LoggedStmt) Exec(args ...interface{}) (sql.Result, error) {
+	return s.ExecContext(context.Background(), args...)
+}
+
+// QueryContext executes the prepared query statement.
+func (s *LoggedStmt) QueryContext(ctx context.Context, args ...interface{}) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := s.Stmt.QueryContext(ctx, args...)
+	s.log.log(ctx, "query", s.query, args, start, nil, err)
+	return rows, err
+}
+
+// ExecContext executes the prepared statement.
+func (s *LoggedStmt) ExecContext(ctx context.Context, args ...interface{}) (sql.Result, error) {
+	start := time.Now()
+	res, err := s.Stmt.ExecContext(ctx, args...)
+	s.log.log(ctx, "exec", s.query, args, start, res, err)
+	return res, err
+}
+
+type sqlLogger struct {
+	l    Logger
+	opts SQLOptions
+}
+
+// log logs a database operation.
+func (s sqlLogger) log(ctx context.Context, op, query string, args []interface{}, start time.Time, res sql.Result, err error) {
+	elapsed := time.Since(start)
+	l := FromContextLogger(ctx)
+	if l.writer == nil {
+		l = s.l
+	}
+
+	fields := []interface{}{"db_op", op, "duration", elapsed}
+	if query != "" {
+		if s.opts.Masker != nil {
+			query = s.opts.Masker.MaskString(query)
+		}
+		fields = append(fields, "query", query)
+	}
+	if s.opts.LogArgs && len(args) > 0 {
+		fields = append(fields, "args", s.args(args))
+	}
+	if res != nil && err == nil {
+		if n, rerr := res.RowsAffected(); rerr == nil {
+			fields = append(fields, "rows_affected", n)
+		}
+	}
+	l = l.With(fields...)
+
+	switch {
+	case err != nil:
+		l.WithError(err).Error("database query failed")
+	case s.opts.SlowThreshold > 0 && elapsed > s.opts.SlowThreshold:
+		l.Warn("slow database query")
+	default:
+		l.Debug("database query")
+	}
+}
+
+// args returns the query arguments, masked if needed.
+func (s sqlLogger) args(args []interface{}) []interface{} {
+	if s.opts.Masker == nil {
+		return args
+	}
+	out := make([]interface{}, len(args))
+	for i, a := range args {
+		if str, ok := a.(string); ok {
+			a = s.opts.Masker.MaskString(str)
+		}
+		out[i] = a
+	}
+	return out
+}
@@ -0,0 +1,164 @@
+package logger
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// errFakeQuery is returned by the fake driver for the queries
+// holding "fail".
+var errFakeQuery = errors.New("fake query failed")
+
+// fakeDriver is a database/sql driver accepting any query, the
+// executions affect one row and the queries return no rows.
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{}, nil }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) {
+	if strings.Contains(query, "fail") {
+		return nil, errFakeQuery
+	}
+	return fakeStmt{}, nil
+}
+
+func (fakeConn) Close() error              { return nil }
+func (fakeConn) Begin() (driver.Tx, error) { return fakeTx{}, nil }
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeStmt struct{}
+
+func (fakeStmt) Close() error  { return nil }
+func (fakeStmt) NumInput() int { return -1 }
+
+func (fakeStmt) Exec([]driver.Value) (driver.Result, error) { return driver.RowsAffected(1), nil }
+func (fakeStmt) Query([]driver.Value) (driver.Rows, error)  { return fakeRows{}, nil }
+
+type fakeRows struct{}
+
+func (fakeRows) Columns() []string         { return []string{"id"} }
+func (fakeRows) Close() error              { return nil }
+func (fakeRows) Next([]driver.Value) error { return io.EOF }
+
+var fakeDriverID atomic.Int64
+
+// openFakeDB returns a LoggedDB using the fake driver, logging to rec.
+func openFakeDB(t *testing.T, rec *Recorder, opts SQLOptions) *LoggedDB {
+	name := fmt.Sprintf("fake%d", fakeDriverID.Add(1))
+	sql.Register(name, fakeDriver{})
+	db, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return WrapDB(db, NewWithWriter(Config{Level: DebugLevel}, rec), opts)
+}
+
+// sqlOps returns the db_op field of the entries.
+func sqlOps(rec *Recorder) []string {
+	var ops []string
+	for _, e := range rec.Entries() {
+		op, _ := e.Field("db_op")
+		ops = append(ops, op.(string))
+	}
+	return ops
+}
+
+func TestLoggedDB(t *testing.T) {
+	rec := NewRecorder()
+	db := openFakeDB(t, rec, SQLOptions{LogArgs: true, Masker: NewMasker()})
+
+	if _, err := db.Exec("UPDATE users SET name = ? WHERE id = ?", "ann", 1); err != nil {
+		t.Fatal(err)
+	}
+	e, _ := rec.LastEntry()
+	if e.Level != DebugLevel || e.Message() != "database query" {
+		t.Errorf("got entry %v", e)
+	}
+	if v, _ := e.Field("rows_affected"); v != int64(1) {
+		t.Errorf("got rows_affected %v", v)
+	}
+	if v, _ := e.Field("args"); len(v.([]interface{})) != 2 {
+		t.Errorf("got args %v", v)
+	}
+
+	if _, err := db.Query("SELECT fail"); !errors.Is(err, errFakeQuery) {
+		t.Fatalf("got error %v", err)
+	}
+	e, _ = rec.LastEntry()
+	if v, _ := e.Field("error"); e.Level != ErrorLevel || v != errFakeQuery.Error() {
+		t.Errorf("got failure entry %v", e)
+	}
+}
+
+func TestLoggedTx(t *testing.T) {
+	rec := NewRecorder()
+	db := openFakeDB(t, rec, SQLOptions{})
+	stmt, err := db.Prepare("INSERT INTO users VALUES (?)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.Reset()
+
+	ctx := context.Background()
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Stmt(stmt).Exec(1); err != nil {
+		t.Fatal(err)
+	}
+	txStmt, err := tx.Prepare("DELETE FROM users WHERE id = ?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := txStmt.Exec(2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Prepare("SELECT fail"); !errors.Is(err, errFakeQuery) {
+		t.Fatalf("got error %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Rollback(); !errors.Is(err, sql.ErrTxDone) {
+		t.Fatalf("got rollback error %v", err)
+	}
+
+	want := []string{"begin", "exec", "prepare", "exec", "prepare", "commit"}
+	if got := sqlOps(rec); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("got operations %v, want %v", got, want)
+	}
+	for _, e := range rec.Entries() {
+		if e.Level == ErrorLevel {
+			if q, _ := e.Field("query"); q != "SELECT fail" {
+				t.Errorf("got error entry %v", e)
+			}
+		}
+	}
+
+	rec.Reset()
+	tx, err = db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if got := sqlOps(rec); strings.Join(got, ",") != "begin,rollback" {
+		t.Errorf("got operations %v, want a logged rollback", got)
+	}
+}
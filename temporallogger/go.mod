@@ -0,0 +1,16 @@
+module github.com/Aibier/go-logger/temporallogger
+
+go 1.26.0
+
+require (
+	github.com/Aibier/go-logger v0.0.0
+	go.temporal.io/sdk v1.49.0
+)
+
+require (
+	go.uber.org/multierr v1.10.0 // indirect
+	go.uber.org/zap v1.27.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/Aibier/go-logger => ../
@@ -0,0 +1,71 @@
+// Package temporallogger provides a Temporal SDK log.Logger
+// writing through the github.com/Aibier/go-logger Logger.
+package temporallogger
+
+import (
+	logger "github.com/Aibier/go-logger"
+	"go.temporal.io/sdk/log"
+)
+
+// TemporalLogger is a Temporal log.Logger writing through a Logger.
+type TemporalLogger struct {
+	l logger.Logger
+}
+
+var (
+	_ log.Logger          = TemporalLogger{}
+	_ log.WithLogger      = TemporalLogger{}
+	_ log.WithSkipCallers = TemporalLogger{}
+)
+
+// NewTemporalLogger returns a log.Logger writing to l, with a "logger"
+// field holding "temporal" and the key/value pairs as fields, and
+// reporting the caller of its methods:
+//
+//	c, err := client.Dial(client.Options{
+//		Logger: temporallogger.NewTemporalLogger(lg),
+//	})
+func NewTemporalLogger(l logger.Logger) log.Logger {
+	return TemporalLogger{l: l.Named("temporal").WithCallerSkip(1)}
+}
+
+// Debug implements log.Logger.
+func (t TemporalLogger) Debug(msg string, keyvals ...interface{}) {
+	t.with(keyvals).Debug(msg)
+}
+
+// Info implements log.Logger.
+func (t TemporalLogger) Info(msg string, keyvals ...interface{}) {
+	t.with(keyvals).Info(msg)
+}
+
+// Warn implements log.Logger.
+func (t TemporalLogger) Warn(msg string, keyvals ...interface{}) {
+	t.with(keyvals).Warn(msg)
+}
+
+// Error implements log.Logger.
+func (t TemporalLogger) Error(msg string, keyvals ...interface{}) {
+	t.with(keyvals).Error(msg)
+}
+
+// With implements log.WithLogger, the key/value pairs, e.g. the
+// workflow and run ids, are added to every entry of the returned
+// logger. t is not modified.
+func (t TemporalLogger) With(keyvals ...interface{}) log.Logger {
+	return TemporalLogger{l: t.with(keyvals)}
+}
+
+// WithCallerSkip implements log.WithSkipCallers, the returned
+// logger skips n additional stack frames to report the caller.
+func (t TemporalLogger) WithCallerSkip(n int) log.Logger {
+	return TemporalLogger{l: t.l.WithCallerSkip(n)}
+}
+
+// with returns the Logger with the key/value pairs as fields.
+func (t TemporalLogger) with(keyvals []interface{}) logger.Logger {
+	if len(keyvals) == 0 {
+		return t.l
+	}
+	return t.l.With(keyvals...)
+}
@@ -0,0 +1,81 @@
+package temporallogger
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	logger "github.com/Aibier/go-logger"
+	"go.temporal.io/sdk/log"
+)
+
+func TestTemporalLogger(t *testing.T) {
+	rec := logger.NewRecorder()
+	tl := NewTemporalLogger(logger.NewWithWriter(logger.Config{Level: logger.DebugLevel}, rec))
+	wl := tl.(log.WithLogger).With("WorkflowID", "wf-1")
+	wl.Debug("debug", "attempt", 1)
+	wl.Info("info")
+	wl.Warn("warn")
+	wl.Error("error", "Error", "boom")
+	tl.Info("without workflow")
+
+	entries := rec.Entries()
+	if len(entries) != 5 {
+		t.Fatalf("got %d entries:\n%s", len(entries), rec.Dump())
+	}
+	for i, want := range []logger.Level{logger.DebugLevel, logger.InfoLevel, logger.WarningLevel, logger.ErrorLevel, logger.InfoLevel} {
+		e := entries[i]
+		if e.Level != want {
+			t.Errorf("%s: got level %v, want %v", e.Message(), e.Level, want)
+		}
+		if v, _ := e.Field(logger.NameFieldKey); v != "temporal" {
+			t.Errorf("%s: got logger %v", e.Message(), v)
+		}
+		if v, ok := e.Field("WorkflowID"); i < 4 && v != "wf-1" || i == 4 && ok {
+			t.Errorf("%s: got WorkflowID %v", e.Message(), v)
+		}
+	}
+	if v, _ := entries[0].Field("attempt"); v != 1 {
+		t.Errorf("got attempt %v", v)
+	}
+}
+
+// logHelper logs through a helper, as the Temporal SDK does.
+func logHelper(l log.Logger, msg string) {
+	log.Skip(l, 1).Info(msg)
+}
+
+func TestTemporalLoggerCaller(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.log")
+	lg, err := logger.New(logger.Config{OutputPaths: []string{path}, DisableInitialFields: true, DisableStacktrace: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tl := NewTemporalLogger(lg)
+	tl.Info("info")
+	log.With(tl, "k", 1).Error("error")
+	logHelper(tl, "helper")
+	lg.Sync()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var n int
+	for sc := bufio.NewScanner(f); sc.Scan(); n++ {
+		var e struct{ Msg, Caller string }
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(e.Caller, "/temporallogger_test.go:") {
+			t.Errorf("%s: got caller %q, want the adapter caller", e.Msg, e.Caller)
+		}
+	}
+	if n != 3 {
+		t.Errorf("got %d entries, want 3", n)
+	}
+}
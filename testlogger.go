@@ -0,0 +1,78 @@
+package logger
+
+import "testing"
+
+// testConfig holds the NewTestLogger settings.
+type testConfig struct {
+	level       Level
+	checkErrors bool
+}
+
+// TestOption configures a logger created with NewTestLogger.
+type TestOption func(*testConfig)
+
+// TestLevel sets the minimum level of the test logger.
+// DebugLevel is used by default.
+func TestLevel(level Level) TestOption {
+	return func(c *testConfig) {
+		c.level = level
+	}
+}
+
+// SkipErrorCheck disables the check failing the test
+// when unexpected error entries were logged.
+func SkipErrorCheck() TestOption {
+	return func(c *testConfig) {
+		c.checkErrors = false
+	}
+}
+
+// NewTestLogger creates a logger backed by a Recorder for the given test.
+// When the test completes it fails if more entries at ErrorLevel or above
+// were logged than expected with Recorder.ExpectErrors, dumping the
+// recorded entries.
+// Usage: lg, rec := logger.NewTestLogger(t)
+func NewTestLogger(tb testing.TB, opts ...TestOption) (Logger, *Recorder) {
+	tb.Helper()
+	cfg := testConfig{level: DebugLevel, checkErrors: true}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	rec := NewRecorder()
+	l := NewWithWriter(Config{Level: cfg.level}, rec)
+	if cfg.checkErrors {
+		tb.Cleanup(func() {
+			rec.checkErrors(tb)
+		})
+	}
+	return l, rec
+}
+
+// ExpectErrors sets the number of entries at ErrorLevel or above
+// that a NewTestLogger recorder accepts without failing the test.
+func (rec *Recorder) ExpectErrors(n int) {
+	top := rec.top()
+	top.mu.Lock()
+	top.expectedErrors = n
+	top.mu.Unlock()
+}
+
+func (rec *Recorder) checkErrors(tb testing.TB) {
+	tb.Helper()
+	var count int
+	for _, e := range rec.Entries() {
+		if e.Level >= ErrorLevel {
+			count++
+		}
+	}
+
+	top := rec.top()
+	top.mu.Lock()
+	expected := top.expectedErrors
+	top.mu.Unlock()
+
+	if count > expected {
+		tb.Errorf("%d unexpected error entries were logged, %d expected\n%s", count, expected, rec.failureDump())
+	}
+}
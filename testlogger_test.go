@@ -0,0 +1,150 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// fakeTB records the failures reported to a testing.TB,
+// to test the assertions failing.
+type fakeTB struct {
+	testing.TB
+	errors   []string
+	cleanups []func()
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Errorf(format string, args ...interface{}) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func (f *fakeTB) Cleanup(fn func()) {
+	f.cleanups = append(f.cleanups, fn)
+}
+
+// cleanup runs the registered cleanup functions, the last one first.
+func (f *fakeTB) cleanup() {
+	for i := len(f.cleanups) - 1; i >= 0; i-- {
+		f.cleanups[i]()
+	}
+}
+
+func TestNewTestLogger(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []TestOption
+		expected int
+		failed   bool
+	}{
+		{"no errors expected", nil, 0, true},
+		{"errors expected", nil, 2, false},
+		{"more errors expected", nil, 3, false},
+		{"skip error check", []TestOption{SkipErrorCheck()}, 0, false},
+	}
+	for _, tt := range tests {
+		tb := &fakeTB{}
+		lg, rec := NewTestLogger(tb, tt.opts...)
+		rec.ExpectErrors(tt.expected)
+		lg.Debug("debug")
+		lg.Warn("warning")
+		lg.Error("first error")
+		lg.With("k", 1).Errorf("second %s", "error")
+		tb.cleanup()
+
+		if failed := len(tb.errors) > 0; failed != tt.failed {
+			t.Errorf("%s: got failures %q", tt.name, tb.errors)
+		}
+		if !tt.failed {
+			continue
+		}
+		msg := tb.errors[0]
+		if !strings.HasPrefix(msg, "2 unexpected error entries were logged, 0 expected\n") ||
+			!strings.Contains(msg, "[error  ] second error {k, 1}") ||
+			!strings.Contains(msg, "(1 entries below warning level hidden)") {
+			t.Errorf("%s: got failure %q", tt.name, msg)
+		}
+	}
+
+	lg, rec := NewTestLogger(t, TestLevel(WarningLevel))
+	lg.Info("hidden")
+	lg.Warn("visible")
+	if n := len(rec.Entries()); n != 1 {
+		t.Errorf("got %d entries with the warning level", n)
+	}
+}
+
+func TestStrictSemantics(t *testing.T) {
+	rec := NewRecorder(WithStrictSemantics())
+	lg := NewWithWriter(Config{}, rec).With("k", 1)
+
+	p, ok := recoverPanic(func() { lg.Panicf("panic %d", 1) }).(RecordedPanic)
+	if !ok || p.Error() != "panic 1" || fmt.Sprint(p.Entry.Fields) != "[k 1]" {
+		t.Errorf("got panic value %#v, want a RecordedPanic", p)
+	}
+	f, ok := recoverPanic(func() { lg.Fatal("fatal") }).(RecordedFatal)
+	if !ok || f.Error() != "fatal" || f.Entry.Level != FatalLevel {
+		t.Errorf("got panic value %#v, want a RecordedFatal", f)
+	}
+	if v := recoverPanic(func() { lg.Error("error") }); v != nil {
+		t.Errorf("an error entry panicked with %#v", v)
+	}
+	if n := len(rec.Entries()); n != 3 {
+		t.Errorf("got %d entries, want them recorded before panicking", n)
+	}
+
+	var handled []LogEntry
+	rec = NewRecorder(WithStrictSemantics(), WithFatalHandler(func(e LogEntry) {
+		if len(rec.Entries()) != 1 {
+			t.Errorf("the fatal handler was called before recording the entry")
+		}
+		handled = append(handled, e)
+	}))
+	lg = NewWithWriter(Config{}, rec)
+	if v := recoverPanic(func() { lg.Fatalw("fatal", "k", 1) }); v != nil {
+		t.Errorf("the fatal handler was bypassed by the panic %#v", v)
+	}
+	if len(handled) != 1 || handled[0].Message() != "fatal" || handled[0].FieldMap()["k"] != 1 {
+		t.Errorf("got handled entries %v", handled)
+	}
+
+	rec = NewRecorder(WithFatalHandler(func(LogEntry) { t.Error("the fatal handler was called without strict semantics") }))
+	NewWithWriter(Config{}, rec).Fatal("fatal")
+	if v := recoverPanic(func() { NewWithWriter(Config{}, rec).Panic("panic") }); v != "panic" {
+		t.Errorf("got panic value %#v, want the message", v)
+	}
+}
+
+func TestNewTestLoggerDefaults(t *testing.T) {
+	tb := &fakeTB{}
+	lg, rec := NewTestLogger(tb)
+	lg.Debug("debug")
+	lg.Warn("warning")
+	if n := len(rec.Entries()); n != 2 {
+		t.Errorf("got %d entries, want the debug one recorded", n)
+	}
+	if len(tb.cleanups) != 1 {
+		t.Fatalf("got %d cleanups registered", len(tb.cleanups))
+	}
+	tb.cleanup()
+	if len(tb.errors) != 0 {
+		t.Errorf("got failures %q without error entries", tb.errors)
+	}
+
+	tb = &fakeTB{}
+	lg, rec = NewTestLogger(tb)
+	rec.ExpectErrors(1)
+	lg.Error("error")
+	lg.DPanic("dpanic")
+	tb.cleanup()
+	if len(tb.errors) != 1 || !strings.HasPrefix(tb.errors[0], "2 unexpected error entries were logged, 1 expected\n[error  ] error {}\n[dpanic ] dpanic {}\n") {
+		t.Errorf("got failures %q, want the dpanic entry counted", tb.errors)
+	}
+
+	tb = &fakeTB{}
+	NewTestLogger(tb, SkipErrorCheck())
+	if len(tb.cleanups) != 0 {
+		t.Errorf("got %d cleanups registered without the error check", len(tb.cleanups))
+	}
+}
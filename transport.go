@@ -0,0 +1,229 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultMaxBodySize the default size limit of the logged bodies.
+const defaultMaxBodySize = 4 << 10
+
+// TransportOptions configures the logging of a transport
+// created with NewLoggingTransport.
+type TransportOptions struct {
+	// LogHeaders when true the request and response headers are logged.
+	LogHeaders bool
+
+	// LogBodies when true the beginning of the request and response
+	// bodies, up to MaxBodySize bytes, is logged. The bodies are
+	// captured as they are read, the entry of a response with a body
+	// is logged once it is read to its end or closed.
+	LogBodies bool
+
+	// MaxBodySize the maximum number of bytes logged of every body.
+	// 4KB by default.
+	MaxBodySize int
+
+	// Masker masquerades the secrets from the logged headers and
+	// bodies. DefaultMasker by default.
+	Masker *Masker
+}
+
+type attemptKeyType struct{}
+
+var attemptKey attemptKeyType
+
+// NewContextWithAttempt returns a new Context that carries the retry
+// attempt of a request, logged by the logging transports.
+func NewContextWithAttempt(parent context.Context, attempt int) context.Context {
+	return context.WithValue(parent, attemptKey, attempt)
+}
+
+type loggingTransport struct {
+	base http.RoundTripper
+	l    Logger
+	opts TransportOptions
+}
+
+// NewLoggingTransport returns a transport logging the requests sent
+// through base, http.DefaultTransport if nil, with their method, host,
+// path, status, duration and retry attempt, see NewContextWithAttempt.
+// The logger of the request context is used, see FromContextLogger, or l
+// if none. Requests are logged as debug, as warnings for 4xx status codes
+// and as errors for 5xx status codes or transport errors.
+// The query parameters of DefaultURLParams are masked.
+func NewLoggingTransport(base http.RoundTripper, l Logger, opts TransportOptions) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if opts.MaxBodySize <= 0 {
+		opts.MaxBodySize = defaultMaxBodySize
+	}
+	if opts.Masker == nil {
+		opts.Masker = DefaultMasker
+	}
+	return loggingTransport{base: base, l: l, opts: opts}
+}
+
+func (t loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	l := FromContextLogger(ctx)
+	if l.writer == nil {
+		l = t.l
+	}
+
+	fields := []interface{}{
+		"method", req.Method,
+		"host", req.URL.Host,
+		"path", req.URL.Path,
+	}
+	if req.URL.RawQuery != "" {
+		fields = append(fields, "query", SanitizeURL("?" + req.URL.RawQuery)[1:])
+	}
+	if attempt, ok := ctx.Value(attemptKey).(int); ok {
+		fields = append(fields, "attempt", attempt)
+	}
+	if t.opts.LogHeaders {
+		fields = append(fields, "request_headers", t.headers(req.Header))
+	}
+	var reqBody *cappedBuffer
+	if t.opts.LogBodies && req.Body != nil && req.Body != http.NoBody {
+		// RoundTrip must not modify the request
+		req = req.Clone(ctx)
+		reqBody = &cappedBuffer{max: t.opts.MaxBodySize}
+		req.Body = teeBody{ReadCloser: req.Body, buf: reqBody}
+	}
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	fields = append(fields, "duration", time.Since(start))
+	if reqBody != nil {
+		fields = append(fields, "request_body", t.opts.Masker.MaskString(reqBody.String()))
+	}
+	if err != nil {
+		l.With(fields...).WithError(err).Error("http request failed")
+		return resp, err
+	}
+
+	fields = append(fields, "status", resp.StatusCode)
+	if t.opts.LogHeaders {
+		fields = append(fields, "response_headers", t.headers(resp.Header))
+	}
+
+	level := DebugLevel
+	switch {
+	case resp.StatusCode >= http.StatusInternalServerError:
+		level = ErrorLevel
+	case resp.StatusCode >= http.StatusBadRequest:
+		level = WarningLevel
+	}
+	if !t.opts.LogBodies || resp.Body == nil || resp.Body == http.NoBody {
+		l.With(fields...).Log(level, "http request")
+		return resp, nil
+	}
+	resp.Body = &loggedBody{
+		ReadCloser: resp.Body,
+		buf:        cappedBuffer{max: t.opts.MaxBodySize},
+		log: func(body string, err error) {
+			fields = append(fields, "response_body", t.opts.Masker.MaskString(body))
+			l.With(fields...).WithError(err).Log(level, "http request")
+		},
+	}
+	return resp, nil
+}
+
+// headers returns the masked header lines, sorted by name.
+func (t loggingTransport) headers(h http.Header) string {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	for _, name := range names {
+		for _, v := range h[name] {
+			sb.WriteString(name)
+			sb.WriteString(": ")
+			sb.WriteString(v)
+			sb.WriteString("\n")
+		}
+	}
+	return t.opts.Masker.MaskString(strings.TrimSuffix(sb.String(), "\n"))
+}
+
+// cappedBuffer keeps the first bytes written, up to max. It's safe
+// for concurrent use, the transports may write the request body
+// while the response is read.
+type cappedBuffer struct {
+	mu  sync.Mutex
+	buf []byte
+	max int
+}
+
+func (b *cappedBuffer) Write(p []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if n := b.max - len(b.buf); n > 0 {
+		if len(p) > n {
+			p = p[:n]
+		}
+		b.buf = append(b.buf, p...)
+	}
+}
+
+func (b *cappedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return string(b.buf)
+}
+
+// teeBody is a body writing what is read from it to a cappedBuffer.
+type teeBody struct {
+	io.ReadCloser
+	buf *cappedBuffer
+}
+
+func (b teeBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.buf.Write(p[:n])
+	return n, err
+}
+
+// loggedBody is a response body calling log with its beginning once
+// it's read to its end, with the read error if any, or closed.
+type loggedBody struct {
+	io.ReadCloser
+	buf  cappedBuffer
+	once sync.Once
+	log  func(body string, err error)
+}
+
+func (b *loggedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.buf.Write(p[:n])
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			b.done(nil)
+		} else {
+			b.done(err)
+		}
+	}
+	return n, err
+}
+
+func (b *loggedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.done(nil)
+	return err
+}
+
+func (b *loggedBody) done(err error) {
+	b.once.Do(func() { b.log(b.buf.String(), err) })
+}
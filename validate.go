@@ -0,0 +1,81 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+
+	"go.uber.org/multierr"
+)
+
+// Validate checks the config, returning an error listing all the
+// problems found: an unknown Mode or Log mode, encoding, duration
+// format or level, an output path that can't be opened, a sampling
+// dropping every entry or a nil middleware. The suspicious settings
+// are reported to OnValidateWarning. New validates its config.
+func (c Config) Validate() error {
+	var err error
+	switch c.Mode {
+	case "", ModeProduction, ModeDevelopment:
+	default:
+		err = multierr.Append(err, fmt.Errorf("unknown mode %q, use ModeProduction or ModeDevelopment", string(c.Mode)))
+	}
+	if _, merr := parseMode(c.Log); merr != nil {
+		err = multierr.Append(err, fmt.Errorf("log: %w", merr))
+	}
+	switch c.Encoding {
+	case "", "json", "console":
+	default:
+		err = multierr.Append(err, fmt.Errorf("unknown encoding %q, use \"json\" or \"console\"", c.Encoding))
+	}
+	if _, ok := durationEncoders[c.DurationFormat]; c.DurationFormat != "" && !ok {
+		err = multierr.Append(err, fmt.Errorf("unknown duration format %q, use \"millis\", \"seconds\", \"nanos\" or \"string\"", c.DurationFormat))
+	}
+	if !c.Level.valid() {
+		err = multierr.Append(err, fmt.Errorf("unknown level %d", int(c.Level)))
+	}
+	if c.StacktraceLevel != nil && !c.StacktraceLevel.valid() {
+		err = multierr.Append(err, fmt.Errorf("unknown stacktrace level %d", int(*c.StacktraceLevel)))
+	}
+	if c.OutputPaths != nil && len(c.OutputPaths) == 0 {
+		c.warn("empty output paths, writing to the default output")
+	}
+	if c.ErrorOutputPaths != nil && len(c.ErrorOutputPaths) == 0 {
+		c.warn("empty error output paths, writing to stderr")
+	}
+	err = multierr.Append(err, validateOutputPaths(c.OutputPaths))
+	if perr := validateOutputPaths(c.ErrorOutputPaths); perr != nil {
+		err = multierr.Append(err, fmt.Errorf("error outputs: %w", perr))
+	}
+	for i, o := range c.Outputs {
+		if perr := validateOutputPath(o.Path); perr != nil {
+			err = multierr.Append(err, fmt.Errorf("output %d %q: %w", i, o.Path, perr))
+		}
+		if !o.MinLevel.valid() {
+			err = multierr.Append(err, fmt.Errorf("output %d %q: unknown level %d", i, o.Path, int(o.MinLevel)))
+		}
+	}
+	if s := c.Sampling; s != nil {
+		if s.Initial < 0 || s.Thereafter < 0 {
+			err = multierr.Append(err, fmt.Errorf("negative sampling initial %d or thereafter %d", s.Initial, s.Thereafter))
+		}
+		if s.Initial == 0 && s.Thereafter == 0 {
+			err = multierr.Append(err, errors.New("sampling initial and thereafter are both 0, dropping every entry"))
+		}
+		if s.MaxLevel != nil && !s.MaxLevel.valid() {
+			err = multierr.Append(err, fmt.Errorf("unknown sampling max level %d", int(*s.MaxLevel)))
+		}
+	}
+	for i, m := range c.CtxMiddlewares {
+		if m == nil {
+			err = multierr.Append(err, fmt.Errorf("nil middleware %d", i))
+		}
+	}
+	return err
+}
+
+// warn reports a suspicious setting to the OnValidateWarning hook.
+func (c Config) warn(warning string) {
+	if c.OnValidateWarning != nil {
+		c.OnValidateWarning(warning)
+	}
+}
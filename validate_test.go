@@ -0,0 +1,62 @@
+package logger
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/multierr"
+)
+
+func TestValidate(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing", "app.log")
+	stacktrace := Level(-1)
+	cfg := Config{
+		Mode:            "dev",
+		Log:             "Devel",
+		Encoding:        "text",
+		DurationFormat:  "hours",
+		Level:           Level(9),
+		StacktraceLevel: &stacktrace,
+		OutputPaths:     []string{"stdout", missing},
+		Outputs:         []Output{{Path: "stderr", MinLevel: Level(7)}},
+		Sampling:        &SamplingConfig{Initial: -1},
+		CtxMiddlewares:  []CtxMiddleware{nil},
+	}
+	want := []string{
+		`unknown mode "dev", use ModeProduction or ModeDevelopment`,
+		`log: unknown mode "Devel", use dev or prod`,
+		`unknown encoding "text", use "json" or "console"`,
+		`unknown duration format "hours"`,
+		`unknown level 9`,
+		`unknown stacktrace level -1`,
+		`output path 1 "` + missing + `": directory `,
+		`output 0 "stderr": unknown level 7`,
+		`negative sampling initial -1 or thereafter 0`,
+		`nil middleware 0`,
+	}
+
+	err := cfg.Validate()
+	errs := multierr.Errors(err)
+	if len(errs) != len(want) {
+		t.Fatalf("got %d errors %v, want %d", len(errs), errs, len(want))
+	}
+	for i, w := range want {
+		if !strings.HasPrefix(errs[i].Error(), w) {
+			t.Errorf("error %d: got %q, want prefix %q", i, errs[i], w)
+		}
+	}
+
+	_, nerr := New(cfg)
+	if nerr == nil || !strings.HasPrefix(nerr.Error(), "invalid logger config: ") {
+		t.Fatalf("New: got error %v", nerr)
+	}
+	if got := multierr.Errors(errors.Unwrap(nerr)); len(got) != len(want) {
+		t.Errorf("New: got %d wrapped errors, want %d", len(got), len(want))
+	}
+
+	if err := (Config{Mode: ModeDevelopment, Level: InfoLevel}).Validate(); err != nil {
+		t.Errorf("valid config: got error %v", err)
+	}
+}